@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"ms_template/internal/config"
+)
+
+// configCommand выполняет config validate, config render или config schema
+func configCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "Ожидается подкоманда: config validate | render | schema")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "validate":
+		return configValidate(args[1:], stdout, stderr)
+	case "render":
+		return configRender(args[1:], stdout, stderr)
+	case "schema":
+		return configSchema(args[1:], stdout, stderr)
+	}
+	fmt.Fprintf(stderr, "Неизвестная подкоманда config %q, ожидается validate | render | schema\n", args[0])
+	return exitUsage
+}
+
+// configValidate только проверяет конфигурацию
+func configValidate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("config validate", stderr)
+	cf := addConfigFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	if loaded, code := cf.load(stderr); loaded == nil {
+		return code
+	}
+	fmt.Fprintln(stdout, "Конфигурация корректна")
+	return exitOK
+}
+
+// configRender выводит конфигурацию после слияния файлов профиля
+func configRender(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("config render", stderr)
+	cf := addConfigFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	loaded, code := cf.load(stderr)
+	if loaded == nil {
+		return code
+	}
+	if err := loaded.Render(stdout); err != nil {
+		fmt.Fprintf(stderr, "Не удалось вывести конфигурацию: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// configSchema выводит JSON Schema файла конфигурации
+func configSchema(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("config schema", stderr)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	schema, err := config.Schema()
+	if err != nil {
+		fmt.Fprintf(stderr, "Не удалось построить схему: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, string(schema))
+	return exitOK
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"ms_template/internal/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthcheck вызывает grpc.health.v1.Health/Check и завершается с кодом
+// exitOK, если сервис отвечает SERVING. Адрес и режим TLS берутся из
+// конфигурации, --addr позволяет обойтись без нее. Явно заданный --tls
+// переопределяет режим из конфигурации. Для режима mtls клиентский
+// сертификат задается через --cert и --key.
+func healthcheck(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("healthcheck", stderr)
+	cf := addConfigFlags(fs)
+	addr := fs.String("addr", "", "адрес gRPC сервера; по умолчанию localhost:grpc.port")
+	service := fs.String("service", "", "имя сервиса для проверки; пустое - сервер целиком")
+	timeout := fs.Duration("timeout", 3*time.Second, "время ожидания ответа")
+	useTLS := fs.Bool("tls", false, "подключаться по TLS; если задан, переопределяет grpc.tls.mode из конфигурации")
+	certFile := fs.String("cert", "", "клиентский сертификат для режима mtls")
+	keyFile := fs.String("key", "", "ключ клиентского сертификата для режима mtls")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	if (*certFile == "") != (*keyFile == "") {
+		fmt.Fprintln(stderr, "Флаги --cert и --key задаются вместе")
+		return exitUsage
+	}
+
+	tlsCfg := config.TLSConfig{Mode: config.TLSModeInsecure}
+	if *addr == "" || *cf.path != "" {
+		loaded, code := cf.load(stderr)
+		if loaded == nil {
+			return code
+		}
+		if *addr == "" {
+			*addr = net.JoinHostPort("localhost", strconv.Itoa(*loaded.Config.GRPC.Port))
+		}
+		tlsCfg = loaded.Config.GRPC.TLS
+	}
+	if flagSet(fs, "tls") {
+		tlsCfg.Mode = config.TLSModeInsecure
+		if *useTLS {
+			tlsCfg.Mode = config.TLSModeTLS
+		}
+	}
+	if *certFile != "" && tlsCfg.Mode == config.TLSModeInsecure {
+		fmt.Fprintln(stderr, "Клиентский сертификат предъявляется только по TLS: задайте --tls")
+		return exitUsage
+	}
+	if *certFile == "" && tlsCfg.Mode == config.TLSModeMTLS {
+		fmt.Fprintln(stderr, "Режим mtls требует клиентского сертификата: задайте --cert и --key")
+		return exitUsage
+	}
+	creds, err := healthcheckCreds(tlsCfg.Mode, *certFile, *keyFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Не удалось загрузить сертификат: %v\n", err)
+		return exitConfig
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		fmt.Fprintf(stderr, "Некорректный адрес %s: %v\n", *addr, err)
+		return exitUsage
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: *service})
+	if err != nil {
+		fmt.Fprintf(stderr, "Проверка %s не выполнена: %v\n", *addr, err)
+		return exitFailure
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		fmt.Fprintln(stderr, resp.GetStatus())
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, resp.GetStatus())
+	return exitOK
+}
+
+// flagSet сообщает, задан ли флаг name в командной строке
+func flagSet(fs *flag.FlagSet, name string) bool {
+	var set bool
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// healthcheckCreds возвращает учетные данные для подключения к своему
+// же процессу. Сертификат сервера не проверяется: проба обращается
+// к localhost, где имя в сертификате обычно не совпадает. Клиентский
+// сертификат certFile предъявляется, если задан; для режима mtls он
+// должен быть подписан client_ca_file сервера и допускать использование
+// для аутентификации клиента (extKeyUsage clientAuth).
+func healthcheckCreds(mode, certFile, keyFile string) (credentials.TransportCredentials, error) {
+	if mode == config.TLSModeInsecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsCfg := &tls.Config{InsecureSkipVerify: true} //nolint:gosec // см. комментарий функции
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
@@ -1,78 +1,127 @@
 package main
 
 import (
-	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
-	"ms_template/internal/app"
 	"ms_template/internal/config"
-	"ms_template/internal/logger"
-	"golang.org/x/sync/errgroup"
 )
 
-func main() {
+// Коды завершения, общие для всех команд
+const (
+	exitOK      = 0 // успешное выполнение
+	exitFailure = 1 // ошибка выполнения: сервис остановился с ошибкой, проверка не пройдена
+	exitUsage   = 2 // неизвестная команда или некорректные флаги
+	exitConfig  = 3 // конфигурация не загружена или некорректна
+)
 
-	path, exist := os.LookupEnv("CONF_PATH")
-	if !exist {
-		log.Fatalf("Не задана обязательная переменная CONF_PATH")
-	}
+// command - подкоманда CLI. run получает аргументы после имени команды
+// и возвращает код завершения.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
 
-	cfg, err := config.LoadConfig(path)
-	if err != nil {
-		log.Fatalf("Не удалось загрузить конфиг: %v", err)
+func commands() []command {
+	return []command{
+		{"serve", "запустить сервис (команда по умолчанию)", serve},
+		{"migrate", "применить миграции хранилища", migrate},
+		{"config", "validate | render | schema - проверить, вывести конфигурацию или ее JSON Schema", configCommand},
+		{"version", "вывести версию сборки", printVersion},
+		{"healthcheck", "проверить сервис через gRPC health, например в HEALTHCHECK образа", healthcheck},
 	}
+}
 
-	logger := logger.Setup(cfg.Env)
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run выполняет команду из args и возвращает код завершения.
+// Без команды, в том числе с одними флагами, выполняется serve.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(args, stdout, stderr)
+	}
 
-	app := app.New(logger, cfg)
+	name := args[0]
+	if name == "help" {
+		usage(stdout)
+		return exitOK
+	}
+	for _, c := range commands() {
+		if c.name == name {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "Неизвестная команда %q\n\n", name)
+	usage(stderr)
+	return exitUsage
+}
 
-	// Контекст для graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Использование: %s <команда> [флаги]\n\nКоманды:\n", os.Args[0])
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nФлаги команды: %s <команда> -h\n", os.Args[0])
+	fmt.Fprintf(w, "Коды завершения: %d - успех, %d - ошибка выполнения, %d - неверные аргументы, %d - ошибка конфигурации\n",
+		exitOK, exitFailure, exitUsage, exitConfig)
+}
 
-	// Канал для получения сигналов ОС
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+// newFlagSet создает набор флагов команды, ошибки разбора выводятся в stderr
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
 
-	// Группа для управления горутинами
-	g, gCtx := errgroup.WithContext(ctx)
+// parse разбирает флаги. ok = false означает, что команда должна
+// завершиться с кодом code: -h выводит справку, ошибка - код exitUsage.
+func parse(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	err := fs.Parse(args)
+	switch {
+	case err == nil:
+		return exitOK, true
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK, false
+	}
+	return exitUsage, false
+}
 
-	g.Go(func() error {
-		return app.Run()
-	})
+// configFlags - флаги загрузки конфигурации: --config и переопределения
+// параметров вида --grpc.port
+type configFlags struct {
+	path  *string
+	flags *config.Flags
+}
 
-	g.Go(func() error {
-		return app.RunMetricsServer()
-	})
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+	return &configFlags{
+		path:  fs.String("config", os.Getenv("CONF_PATH"), "путь к YAML конфигурации (по умолчанию CONF_PATH)"),
+		flags: config.RegisterFlags(fs),
+	}
+}
 
-	g.Go(func() error {
-		select {
-		case sig := <-sigChan:
-			logger.Info("Получен сигнал завершения", "signal", sig.String())
-			cancel()
-			
-			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer shutdownCancel()
-			
-			if err := app.Shutdown(shutdownCtx); err != nil {
-				logger.Error("Ошибка при graceful shutdown", "error", err)
-				return err
-			}
-			
-			logger.Info("Приложение корректно завершено")
-			return nil
-		case <-gCtx.Done():
-			return gCtx.Err()
-		}
-	})
+func (c *configFlags) options() config.LoadOptions {
+	return config.LoadOptions{Path: *c.path, Environ: os.Environ(), Flags: c.flags}
+}
 
-	// Ожидание завершения всех горутин
-	if err := g.Wait(); err != nil && err != context.Canceled {
-		logger.Error("Ошибка в работе приложения", "error", err)
-		os.Exit(1)
+// load загружает конфигурацию. При ошибке она выводится в stderr
+// и возвращается код exitConfig.
+func (c *configFlags) load(stderr io.Writer) (*config.Loaded, int) {
+	if *c.path == "" {
+		fmt.Fprintln(stderr, "Не задан путь к конфигурации: флаг --config или переменная CONF_PATH")
+		return nil, exitConfig
+	}
+	loaded, err := config.Load(c.options())
+	if err != nil {
+		fmt.Fprintf(stderr, "Не удалось загрузить конфигурацию: %v\n", err)
+		return nil, exitConfig
 	}
-}
\ No newline at end of file
+	return loaded, exitOK
+}
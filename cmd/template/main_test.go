@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ms_template/internal/buildinfo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type CLITestSuite struct {
+	suite.Suite
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	path   string
+}
+
+func TestCLITestSuite(t *testing.T) {
+	suite.Run(t, new(CLITestSuite))
+}
+
+func (s *CLITestSuite) SetupTest() {
+	s.stdout.Reset()
+	s.stderr.Reset()
+	s.path = filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(s.path, []byte("grpc:\n  port: 8080\n  timeout: 5s\n"), 0o600))
+}
+
+func (s *CLITestSuite) run(args ...string) int {
+	return run(args, &s.stdout, &s.stderr)
+}
+
+func (s *CLITestSuite) TestRun_UnknownCommand() {
+	// Act
+	code := s.run("bogus")
+
+	// Assert
+	assert.Equal(s.T(), exitUsage, code)
+	assert.Contains(s.T(), s.stderr.String(), `Неизвестная команда "bogus"`)
+}
+
+func (s *CLITestSuite) TestRun_UnknownFlag() {
+	// Act
+	code := s.run("config", "validate", "--no-such-flag")
+
+	// Assert
+	assert.Equal(s.T(), exitUsage, code)
+}
+
+func (s *CLITestSuite) TestConfigValidate() {
+	// Act
+	code := s.run("config", "validate", "--config", s.path)
+
+	// Assert
+	assert.Equal(s.T(), exitOK, code)
+	assert.Contains(s.T(), s.stdout.String(), "Конфигурация корректна")
+}
+
+func (s *CLITestSuite) TestConfigValidate_Invalid() {
+	// Act
+	code := s.run("config", "validate", "--config", s.path, "--grpc.port=0")
+
+	// Assert
+	assert.Equal(s.T(), exitConfig, code)
+	assert.Contains(s.T(), s.stderr.String(), "grpc.port (флаг --grpc.port)")
+}
+
+func (s *CLITestSuite) TestConfigRender() {
+	// Act
+	code := s.run("config", "render", "--config", s.path, "--env=prod")
+
+	// Assert
+	assert.Equal(s.T(), exitOK, code)
+	assert.Contains(s.T(), s.stdout.String(), "env: prod\n")
+}
+
+func (s *CLITestSuite) TestConfigSchema() {
+	// Act
+	code := s.run("config", "schema")
+
+	// Assert
+	assert.Equal(s.T(), exitOK, code)
+	assert.True(s.T(), json.Valid(s.stdout.Bytes()))
+}
+
+func (s *CLITestSuite) TestVersion() {
+	// Act
+	code := s.run("version")
+
+	// Assert
+	assert.Equal(s.T(), exitOK, code)
+	assert.Equal(s.T(), "notes_service "+buildinfo.Get().String()+"\n", s.stdout.String())
+}
+
+func (s *CLITestSuite) TestHealthcheck() {
+	// Arrange
+	checks := health.NewServer()
+	checks.SetServingStatus("notes.Notes", healthpb.HealthCheckResponse_NOT_SERVING)
+	addr := s.startHealthServer(checks)
+
+	// Act & Assert
+	assert.Equal(s.T(), exitOK, s.run("healthcheck", "--addr", addr))
+	assert.Equal(s.T(), "SERVING\n", s.stdout.String())
+	assert.Equal(s.T(), exitFailure, s.run("healthcheck", "--addr", addr, "--service", "notes.Notes"))
+	assert.Contains(s.T(), s.stderr.String(), "NOT_SERVING")
+}
+
+func (s *CLITestSuite) TestHealthcheck_TLS() {
+	// Arrange
+	addr := s.startHealthServer(health.NewServer(), grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{s.selfSignedCert()},
+	})))
+
+	// Act
+	plain := s.run("healthcheck", "--addr", addr, "--timeout", "200ms")
+	withTLS := s.run("healthcheck", "--addr", addr, "--tls")
+
+	// Assert
+	assert.Equal(s.T(), exitFailure, plain)
+	assert.Equal(s.T(), exitOK, withTLS)
+}
+
+func (s *CLITestSuite) TestHealthcheck_TLSOverridesConfig() {
+	// Arrange: в конфигурации grpc.tls.mode по умолчанию insecure
+	addr := s.startHealthServer(health.NewServer(), grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{s.selfSignedCert()},
+	})))
+
+	// Act
+	code := s.run("healthcheck", "--config", s.path, "--addr", addr, "--tls")
+
+	// Assert
+	assert.Equal(s.T(), exitOK, code)
+}
+
+func (s *CLITestSuite) TestHealthcheck_MTLS() {
+	// Arrange: сервер принимает только клиентский сертификат client
+	client := s.selfSignedCert()
+	leaf, err := x509.ParseCertificate(client.Certificate[0])
+	require.NoError(s.T(), err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	addr := s.startHealthServer(health.NewServer(), grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{s.selfSignedCert()},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})))
+	certFile, keyFile := s.writeCert(client)
+
+	// Act
+	withoutCert := s.run("healthcheck", "--addr", addr, "--tls", "--timeout", "200ms")
+	withCert := s.run("healthcheck", "--addr", addr, "--tls", "--cert", certFile, "--key", keyFile)
+	certOnly := s.run("healthcheck", "--addr", addr, "--tls", "--cert", certFile)
+
+	// Assert
+	assert.Equal(s.T(), exitFailure, withoutCert)
+	assert.Equal(s.T(), exitOK, withCert)
+	assert.Equal(s.T(), exitUsage, certOnly)
+}
+
+func (s *CLITestSuite) TestHealthcheck_Unavailable() {
+	// Arrange
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(s.T(), err)
+	addr := lis.Addr().String()
+	require.NoError(s.T(), lis.Close())
+
+	// Act
+	code := s.run("healthcheck", "--addr", addr, "--timeout", "200ms")
+
+	// Assert
+	assert.Equal(s.T(), exitFailure, code)
+}
+
+func (s *CLITestSuite) startHealthServer(checks *health.Server, opts ...grpc.ServerOption) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(s.T(), err)
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, checks)
+	go server.Serve(lis)
+	s.T().Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+// selfSignedCert создает самоподписанный сертификат для localhost
+func (s *CLITestSuite) selfSignedCert() tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(s.T(), err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(s.T(), err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writeCert сохраняет сертификат и ключ в PEM файлы и возвращает их пути
+func (s *CLITestSuite) writeCert(cert tls.Certificate) (string, string) {
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(s.T(), err)
+
+	dir := s.T().TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	require.NoError(s.T(), os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	require.NoError(s.T(), os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600))
+	return certFile, keyFile
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/signal"
+	"syscall"
+
+	"ms_template/internal/api/notes/repository"
+	"ms_template/internal/app"
+	"ms_template/internal/buildinfo"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+)
+
+// serve запускает сервис до сигнала SIGINT, SIGTERM или SIGQUIT
+func serve(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("serve", stderr)
+	cf := addConfigFlags(fs)
+	printConfig := fs.Bool("print-config", false, "вывести итоговую конфигурацию с источниками значений и выйти")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	loaded, code := cf.load(stderr)
+	if loaded == nil {
+		return code
+	}
+	if *printConfig {
+		if err := loaded.Dump(stdout); err != nil {
+			fmt.Fprintf(stderr, "Не удалось вывести конфигурацию: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	log, levels, code := setupLogger(loaded, stderr)
+	if log == nil {
+		return code
+	}
+
+	application, err := app.New(log, levels, loaded.Config)
+	if err != nil {
+		log.Error("Не удалось инициализировать приложение", "error", err)
+		return exitFailure
+	}
+	application.WatchConfig(cf.options())
+
+	// Контекст отменяется сигналом ОС и запускает graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	if err := application.Run(ctx); err != nil {
+		log.Error("Ошибка в работе приложения", "error", err)
+		return exitFailure
+	}
+
+	log.Info("Приложение корректно завершено")
+	return exitOK
+}
+
+// migrate приводит схему хранилища к текущей версии и завершается
+func migrate(args []string, _, stderr io.Writer) int {
+	fs := newFlagSet("migrate", stderr)
+	cf := addConfigFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	loaded, code := cf.load(stderr)
+	if loaded == nil {
+		return code
+	}
+	log, _, code := setupLogger(loaded, stderr)
+	if log == nil {
+		return code
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := repository.NewPostgresRepo().Migrate(ctx); err != nil {
+		log.Error("Не удалось применить миграции", "error", err)
+		return exitFailure
+	}
+	log.Info("Миграции применены")
+	return exitOK
+}
+
+// setupLogger настраивает логирование по конфигурации. Ошибка
+// настройки считается ошибкой конфигурации.
+func setupLogger(loaded *config.Loaded, stderr io.Writer) (*slog.Logger, *logger.Levels, int) {
+	log, levels, err := logger.Setup(loaded.Config.Logging)
+	if err != nil {
+		fmt.Fprintf(stderr, "Не удалось настроить логирование: %v\n", err)
+		return nil, nil, exitConfig
+	}
+	log.Info("Запуск", "build", buildinfo.Get(), "env", loaded.Config.Env, "files", loaded.Files)
+	return log, levels, exitOK
+}
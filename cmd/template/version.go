@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"ms_template/internal/buildinfo"
+)
+
+// printVersion выводит версию сборки: значения из -ldflags или из
+// сведений, которые go build записывает в бинарник, см. buildinfo
+func printVersion(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("version", stderr)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	fmt.Fprintln(stdout, "notes_service", buildinfo.Get())
+	return exitOK
+}
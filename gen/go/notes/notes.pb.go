@@ -281,6 +281,224 @@ func (x *Note) GetContent() string {
 	return ""
 }
 
+type GetUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserID        string                 `protobuf:"bytes,1,opt,name=userID,proto3" json:"userID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageRequest) Reset() {
+	*x = GetUsageRequest{}
+	mi := &file_notes_notes_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageRequest) ProtoMessage() {}
+
+func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetUsageRequest) GetUserID() string {
+	if x != nil {
+		return x.UserID
+	}
+	return ""
+}
+
+type GetUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *Usage                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Tenant        *Usage                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageResponse) Reset() {
+	*x = GetUsageResponse{}
+	mi := &file_notes_notes_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageResponse) ProtoMessage() {}
+
+func (x *GetUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageResponse) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetUsageResponse) GetUser() *Usage {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) GetTenant() *Usage {
+	if x != nil {
+		return x.Tenant
+	}
+	return nil
+}
+
+// Usage is current consumption together with the applied quota.
+type Usage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NotesCount    int64                  `protobuf:"varint,1,opt,name=notes_count,json=notesCount,proto3" json:"notes_count,omitempty"`
+	TotalBytes    int64                  `protobuf:"varint,2,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	Quota         *Quota                 `protobuf:"bytes,3,opt,name=quota,proto3" json:"quota,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Usage) Reset() {
+	*x = Usage{}
+	mi := &file_notes_notes_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Usage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Usage) ProtoMessage() {}
+
+func (x *Usage) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Usage.ProtoReflect.Descriptor instead.
+func (*Usage) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Usage) GetNotesCount() int64 {
+	if x != nil {
+		return x.NotesCount
+	}
+	return 0
+}
+
+func (x *Usage) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *Usage) GetQuota() *Quota {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+// Quota limits storage; zero means no limit.
+type Quota struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxNotes      int64                  `protobuf:"varint,1,opt,name=max_notes,json=maxNotes,proto3" json:"max_notes,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxNoteSize   int64                  `protobuf:"varint,3,opt,name=max_note_size,json=maxNoteSize,proto3" json:"max_note_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quota) Reset() {
+	*x = Quota{}
+	mi := &file_notes_notes_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quota) ProtoMessage() {}
+
+func (x *Quota) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quota.ProtoReflect.Descriptor instead.
+func (*Quota) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Quota) GetMaxNotes() int64 {
+	if x != nil {
+		return x.MaxNotes
+	}
+	return 0
+}
+
+func (x *Quota) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *Quota) GetMaxNoteSize() int64 {
+	if x != nil {
+		return x.MaxNoteSize
+	}
+	return 0
+}
+
 var File_notes_notes_proto protoreflect.FileDescriptor
 
 const file_notes_notes_proto_rawDesc = "" +
@@ -300,10 +518,26 @@ const file_notes_notes_proto_rawDesc = "" +
 	"\x04Note\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
-	"\acontent\x18\x03 \x01(\tR\acontent2~\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\")\n" +
+	"\x0fGetUsageRequest\x12\x16\n" +
+	"\x06userID\x18\x01 \x01(\tR\x06userID\"Z\n" +
+	"\x10GetUsageResponse\x12 \n" +
+	"\x04user\x18\x01 \x01(\v2\f.notes.UsageR\x04user\x12$\n" +
+	"\x06tenant\x18\x02 \x01(\v2\f.notes.UsageR\x06tenant\"m\n" +
+	"\x05Usage\x12\x1f\n" +
+	"\vnotes_count\x18\x01 \x01(\x03R\n" +
+	"notesCount\x12\x1f\n" +
+	"\vtotal_bytes\x18\x02 \x01(\x03R\n" +
+	"totalBytes\x12\"\n" +
+	"\x05quota\x18\x03 \x01(\v2\f.notes.QuotaR\x05quota\"e\n" +
+	"\x05Quota\x12\x1b\n" +
+	"\tmax_notes\x18\x01 \x01(\x03R\bmaxNotes\x12\x1b\n" +
+	"\tmax_bytes\x18\x02 \x01(\x03R\bmaxBytes\x12\"\n" +
+	"\rmax_note_size\x18\x03 \x01(\x03R\vmaxNoteSize2\xbb\x01\n" +
 	"\x05Notes\x128\n" +
 	"\aAddNote\x12\x15.notes.AddNoteRequest\x1a\x16.notes.AddNoteResponse\x12;\n" +
-	"\bGetNotes\x12\x16.notes.GetNotesRequest\x1a\x17.notes.GetNotesResponseB\x16Z\x14./gen/go/notes;notesb\x06proto3"
+	"\bGetNotes\x12\x16.notes.GetNotesRequest\x1a\x17.notes.GetNotesResponse\x12;\n" +
+	"\bGetUsage\x12\x16.notes.GetUsageRequest\x1a\x17.notes.GetUsageResponseB\x16Z\x14./gen/go/notes;notesb\x06proto3"
 
 var (
 	file_notes_notes_proto_rawDescOnce sync.Once
@@ -317,26 +551,35 @@ func file_notes_notes_proto_rawDescGZIP() []byte {
 	return file_notes_notes_proto_rawDescData
 }
 
-var file_notes_notes_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_notes_notes_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_notes_notes_proto_goTypes = []any{
 	(*AddNoteRequest)(nil),   // 0: notes.AddNoteRequest
 	(*AddNoteResponse)(nil),  // 1: notes.AddNoteResponse
 	(*GetNotesRequest)(nil),  // 2: notes.GetNotesRequest
 	(*GetNotesResponse)(nil), // 3: notes.GetNotesResponse
 	(*Note)(nil),             // 4: notes.Note
+	(*GetUsageRequest)(nil),  // 5: notes.GetUsageRequest
+	(*GetUsageResponse)(nil), // 6: notes.GetUsageResponse
+	(*Usage)(nil),            // 7: notes.Usage
+	(*Quota)(nil),            // 8: notes.Quota
 }
 var file_notes_notes_proto_depIdxs = []int32{
 	4, // 0: notes.AddNoteRequest.note:type_name -> notes.Note
 	4, // 1: notes.GetNotesResponse.notes:type_name -> notes.Note
-	0, // 2: notes.Notes.AddNote:input_type -> notes.AddNoteRequest
-	2, // 3: notes.Notes.GetNotes:input_type -> notes.GetNotesRequest
-	1, // 4: notes.Notes.AddNote:output_type -> notes.AddNoteResponse
-	3, // 5: notes.Notes.GetNotes:output_type -> notes.GetNotesResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	7, // 2: notes.GetUsageResponse.user:type_name -> notes.Usage
+	7, // 3: notes.GetUsageResponse.tenant:type_name -> notes.Usage
+	8, // 4: notes.Usage.quota:type_name -> notes.Quota
+	0, // 5: notes.Notes.AddNote:input_type -> notes.AddNoteRequest
+	2, // 6: notes.Notes.GetNotes:input_type -> notes.GetNotesRequest
+	5, // 7: notes.Notes.GetUsage:input_type -> notes.GetUsageRequest
+	1, // 8: notes.Notes.AddNote:output_type -> notes.AddNoteResponse
+	3, // 9: notes.Notes.GetNotes:output_type -> notes.GetNotesResponse
+	6, // 10: notes.Notes.GetUsage:output_type -> notes.GetUsageResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_notes_notes_proto_init() }
@@ -350,7 +593,7 @@ func file_notes_notes_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_notes_notes_proto_rawDesc), len(file_notes_notes_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	Notes_AddNote_FullMethodName  = "/notes.Notes/AddNote"
 	Notes_GetNotes_FullMethodName = "/notes.Notes/GetNotes"
+	Notes_GetUsage_FullMethodName = "/notes.Notes/GetUsage"
 )
 
 // NotesClient is the client API for Notes service.
@@ -31,6 +32,8 @@ const (
 type NotesClient interface {
 	AddNote(ctx context.Context, in *AddNoteRequest, opts ...grpc.CallOption) (*AddNoteResponse, error)
 	GetNotes(ctx context.Context, in *GetNotesRequest, opts ...grpc.CallOption) (*GetNotesResponse, error)
+	// GetUsage returns storage consumption and quotas of the user and its tenant.
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error)
 }
 
 type notesClient struct {
@@ -61,6 +64,16 @@ func (c *notesClient) GetNotes(ctx context.Context, in *GetNotesRequest, opts ..
 	return out, nil
 }
 
+func (c *notesClient) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, Notes_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NotesServer is the server API for Notes service.
 // All implementations must embed UnimplementedNotesServer
 // for forward compatibility.
@@ -69,6 +82,8 @@ func (c *notesClient) GetNotes(ctx context.Context, in *GetNotesRequest, opts ..
 type NotesServer interface {
 	AddNote(context.Context, *AddNoteRequest) (*AddNoteResponse, error)
 	GetNotes(context.Context, *GetNotesRequest) (*GetNotesResponse, error)
+	// GetUsage returns storage consumption and quotas of the user and its tenant.
+	GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error)
 	mustEmbedUnimplementedNotesServer()
 }
 
@@ -85,6 +100,9 @@ func (UnimplementedNotesServer) AddNote(context.Context, *AddNoteRequest) (*AddN
 func (UnimplementedNotesServer) GetNotes(context.Context, *GetNotesRequest) (*GetNotesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetNotes not implemented")
 }
+func (UnimplementedNotesServer) GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUsage not implemented")
+}
 func (UnimplementedNotesServer) mustEmbedUnimplementedNotesServer() {}
 func (UnimplementedNotesServer) testEmbeddedByValue()               {}
 
@@ -142,6 +160,24 @@ func _Notes_GetNotes_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Notes_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotesServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Notes_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotesServer).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Notes_ServiceDesc is the grpc.ServiceDesc for Notes service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,6 +193,10 @@ var Notes_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetNotes",
 			Handler:    _Notes_GetNotes_Handler,
 		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _Notes_GetUsage_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "notes/notes.proto",
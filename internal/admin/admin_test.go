@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	adminpb "ms_template/gen/go/admin"
+	"ms_template/internal/auth"
+	"ms_template/internal/buildinfo"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const token = "secret-token"
+
+type AdminTestSuite struct {
+	suite.Suite
+	levels *logger.Levels
+	server *Server
+	mux    *http.ServeMux
+}
+
+func TestAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminTestSuite))
+}
+
+func (s *AdminTestSuite) SetupTest() {
+	s.levels = logger.NewLevels(slog.LevelInfo)
+	s.server = NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), s.levels, config.AdminConfig{
+		Enabled:         true,
+		Token:           token,
+		AllowedSubjects: []string{"ops-client"},
+		MaxLevelTTL:     time.Hour,
+	})
+	s.mux = http.NewServeMux()
+	s.server.Register(s.mux)
+}
+
+func (s *AdminTestSuite) TestGRPC_Authorization() {
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected codes.Code
+	}{
+		{name: "без токена", ctx: context.Background(), expected: codes.Unauthenticated},
+		{name: "неверный токен", ctx: withToken("wrong"), expected: codes.PermissionDenied},
+		{name: "токен", ctx: withToken(token), expected: codes.OK},
+		{name: "токен без TLS", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer "+token)), expected: codes.PermissionDenied},
+		{name: "разрешенный сертификат", ctx: auth.NewContext(context.Background(), auth.Principal{Subject: "ops-client"}), expected: codes.OK},
+		{name: "чужой сертификат", ctx: auth.NewContext(context.Background(), auth.Principal{Subject: "other"}), expected: codes.Unauthenticated},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			_, err := s.server.GetLogLevel(tc.ctx, &adminpb.GetLogLevelRequest{})
+			assert.Equal(s.T(), tc.expected, status.Code(err))
+		})
+	}
+}
+
+func (s *AdminTestSuite) TestGRPC_SetLogLevel() {
+	// Act
+	resp, err := s.server.SetLogLevel(withToken(token), &adminpb.SetLogLevelRequest{
+		Logger: "ratelimit",
+		Level:  "debug",
+		Ttl:    durationpb.New(time.Minute),
+	})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "info", resp.Levels.Default.Level)
+	require.Len(s.T(), resp.Levels.Overrides, 1)
+	assert.Equal(s.T(), "ratelimit", resp.Levels.Overrides[0].Logger)
+	assert.Equal(s.T(), "debug", resp.Levels.Overrides[0].Level)
+	assert.NotNil(s.T(), resp.Levels.Overrides[0].ExpiresAt)
+	assert.Equal(s.T(), slog.LevelDebug, s.levels.Level("ratelimit"))
+}
+
+func (s *AdminTestSuite) TestGRPC_SetLogLevel_LogsTarget() {
+	// Arrange
+	var buf bytes.Buffer
+	log := logger.Named(slog.New(slog.NewJSONHandler(&buf, nil)), "admin")
+	server := NewServer(log, s.levels, config.AdminConfig{Enabled: true, Token: token})
+
+	// Act
+	_, err := server.SetLogLevel(withToken(token), &adminpb.SetLogLevelRequest{Logger: "ratelimit", Level: "debug"})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, strings.Count(buf.String(), `"logger":`))
+	assert.Contains(s.T(), buf.String(), `"logger":"admin"`)
+	assert.Contains(s.T(), buf.String(), `"target":"ratelimit"`)
+}
+
+func (s *AdminTestSuite) TestGRPC_SetLogLevel_Invalid() {
+	// Act
+	_, err := s.server.SetLogLevel(withToken(token), &adminpb.SetLogLevelRequest{Level: "verbose"})
+
+	// Assert
+	assert.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (s *AdminTestSuite) TestGRPC_MaxTTLApplied() {
+	// Act: изменение без ttl все равно вернется через max_level_ttl
+	_, err := s.server.SetLogLevel(withToken(token), &adminpb.SetLogLevelRequest{Level: "debug"})
+
+	// Assert
+	require.NoError(s.T(), err)
+	expires := s.levels.State().Default.ExpiresAt
+	assert.WithinDuration(s.T(), time.Now().Add(time.Hour), expires, time.Minute)
+}
+
+func (s *AdminTestSuite) TestGRPC_GetVersion() {
+	// Act
+	resp, err := s.server.GetVersion(withToken(token), &adminpb.GetVersionRequest{})
+	_, unauthorized := s.server.GetVersion(context.Background(), &adminpb.GetVersionRequest{})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), buildinfo.Get().Version, resp.Version)
+	assert.Equal(s.T(), runtime.Version(), resp.GoVersion)
+	assert.Equal(s.T(), codes.Unauthenticated, status.Code(unauthorized))
+}
+
+func (s *AdminTestSuite) TestHTTP_SetAndRestore() {
+	// Act
+	set := s.request(http.MethodPut, `{"level":"debug","ttl":"10m"}`, token)
+	restore := s.request(http.MethodPut, `{"restore":true}`, token)
+
+	// Assert
+	require.Equal(s.T(), http.StatusOK, set.Code)
+	var levels levelsJSON
+	require.NoError(s.T(), json.Unmarshal(set.Body.Bytes(), &levels))
+	assert.Equal(s.T(), "debug", levels.Default.Level)
+	assert.NotNil(s.T(), levels.Default.ExpiresAt)
+
+	require.Equal(s.T(), http.StatusOK, restore.Code)
+	assert.Equal(s.T(), slog.LevelInfo, s.levels.Level(""))
+}
+
+func (s *AdminTestSuite) TestHTTP_Errors() {
+	assert.Equal(s.T(), http.StatusUnauthorized, s.request(http.MethodGet, "", "").Code)
+	assert.Equal(s.T(), http.StatusForbidden, s.request(http.MethodGet, "", "wrong").Code)
+	assert.Equal(s.T(), http.StatusBadRequest, s.request(http.MethodPut, `{"level":"debug","ttl":"soon"}`, token).Code)
+	assert.Equal(s.T(), http.StatusMethodNotAllowed, s.request(http.MethodDelete, "", token).Code)
+}
+
+func (s *AdminTestSuite) request(method, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/admin/loglevel", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// withToken возвращает контекст вызова по TLS с bearer токеном
+func withToken(token string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, "Bearer "+token))
+}
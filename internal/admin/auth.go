@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"ms_template/internal/auth"
+	"ms_template/internal/config"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationHeader = "authorization"
+
+// authorizer проверяет доступ к административным вызовам:
+// bearer токен или клиентский сертификат из списка разрешенных
+type authorizer struct {
+	token    string
+	subjects map[string]struct{}
+}
+
+func newAuthorizer(cfg config.AdminConfig) authorizer {
+	subjects := make(map[string]struct{}, len(cfg.AllowedSubjects))
+	for _, s := range cfg.AllowedSubjects {
+		subjects[s] = struct{}{}
+	}
+	return authorizer{token: cfg.Token.Value(), subjects: subjects}
+}
+
+// checkGRPC возвращает Unauthenticated без учетных данных
+// и PermissionDenied для неразрешенного клиента. Токен принимается
+// только по TLS, чтобы не передавать его в открытом виде
+func (a authorizer) checkGRPC(ctx context.Context) error {
+	if p, ok := auth.FromContext(ctx); ok {
+		if _, allowed := a.subjects[p.Subject]; allowed {
+			return nil
+		}
+	}
+
+	values := metadata.ValueFromIncomingContext(ctx, authorizationHeader)
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "требуется токен администратора")
+	}
+	if !overTLS(ctx) {
+		return status.Error(codes.PermissionDenied, "токен администратора принимается только по TLS")
+	}
+	if !a.validToken(values[0]) {
+		return status.Error(codes.PermissionDenied, "доступ запрещен")
+	}
+	return nil
+}
+
+// overTLS сообщает, установлено ли соединение вызова по TLS
+func overTLS(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	_, ok = p.AuthInfo.(credentials.TLSInfo)
+	return ok
+}
+
+// checkHTTP проверяет заголовок Authorization и возвращает HTTP статус ошибки
+func (a authorizer) checkHTTP(r *http.Request) (int, bool) {
+	header := r.Header.Get(authorizationHeader)
+	if header == "" {
+		return http.StatusUnauthorized, false
+	}
+	if !a.validToken(header) {
+		return http.StatusForbidden, false
+	}
+	return http.StatusOK, true
+}
+
+func (a authorizer) validToken(header string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || a.token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
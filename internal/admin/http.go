@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"ms_template/internal/logger"
+)
+
+// levelsJSON - представление уровней в HTTP API
+type levelsJSON struct {
+	Default   levelJSON            `json:"default"`
+	Overrides map[string]levelJSON `json:"overrides"`
+}
+
+type levelJSON struct {
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// levelRequest - тело PUT /admin/loglevel; ttl в формате time.Duration, например "10m"
+type levelRequest struct {
+	Logger  string `json:"logger"`
+	Level   string `json:"level"`
+	TTL     string `json:"ttl"`
+	Restore bool   `json:"restore"`
+}
+
+// Register добавляет административные эндпоинты в mux
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.Handle("/admin/loglevel", s.authorized(http.HandlerFunc(s.handleLogLevel)))
+}
+
+func (s *Server) authorized(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code, ok := s.auth.Load().checkHTTP(r); !ok {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "некорректное тело запроса", http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			parsed, err := time.ParseDuration(req.TTL)
+			if err != nil {
+				http.Error(w, "некорректный ttl", http.StatusBadRequest)
+				return
+			}
+			ttl = parsed
+		}
+
+		change := levelChange{Logger: req.Logger, Level: req.Level, TTL: ttl, Reset: req.Restore}
+		if err := s.setLevel(r.Context(), change); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toJSON(s.levels.State()))
+}
+
+func toJSON(state logger.LevelsState) levelsJSON {
+	levels := levelsJSON{
+		Default:   toLevelJSON(state.Default),
+		Overrides: make(map[string]levelJSON, len(state.Overrides)),
+	}
+	for name, o := range state.Overrides {
+		levels.Overrides[name] = toLevelJSON(o)
+	}
+	return levels
+}
+
+func toLevelJSON(o logger.LevelOverride) levelJSON {
+	l := levelJSON{Level: strings.ToLower(o.Level.String())}
+	if !o.ExpiresAt.IsZero() {
+		l.ExpiresAt = &o.ExpiresAt
+	}
+	return l
+}
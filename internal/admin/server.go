@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	adminpb "ms_template/gen/go/admin"
+	"ms_template/internal/buildinfo"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server реализует административный gRPC сервис и HTTP эндпоинты
+type Server struct {
+	adminpb.UnimplementedAdminServer
+
+	log    *slog.Logger
+	levels *logger.Levels
+	auth   atomic.Pointer[authorizer]
+	maxTTL time.Duration
+}
+
+var _ adminpb.AdminServer = &Server{}
+
+func NewServer(log *slog.Logger, levels *logger.Levels, cfg config.AdminConfig) *Server {
+	s := &Server{
+		log:    log,
+		levels: levels,
+		maxTTL: cfg.MaxLevelTTL,
+	}
+	s.UpdateConfig(cfg)
+	return s
+}
+
+// UpdateConfig применяет новый токен администратора, например
+// после ротации секрета
+func (s *Server) UpdateConfig(cfg config.AdminConfig) {
+	auth := newAuthorizer(cfg)
+	s.auth.Store(&auth)
+}
+
+func (s *Server) GetLogLevel(ctx context.Context, _ *adminpb.GetLogLevelRequest) (*adminpb.GetLogLevelResponse, error) {
+	if err := s.auth.Load().checkGRPC(ctx); err != nil {
+		return nil, err
+	}
+	return &adminpb.GetLogLevelResponse{Levels: toProto(s.levels.State())}, nil
+}
+
+func (s *Server) SetLogLevel(ctx context.Context, req *adminpb.SetLogLevelRequest) (*adminpb.SetLogLevelResponse, error) {
+	if err := s.auth.Load().checkGRPC(ctx); err != nil {
+		return nil, err
+	}
+
+	var ttl time.Duration
+	if req.GetTtl() != nil {
+		ttl = req.GetTtl().AsDuration()
+	}
+	if err := s.setLevel(ctx, levelChange{Logger: req.GetLogger(), Level: req.GetLevel(), TTL: ttl, Reset: req.GetRestore()}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &adminpb.SetLogLevelResponse{Levels: toProto(s.levels.State())}, nil
+}
+
+func (s *Server) GetVersion(ctx context.Context, _ *adminpb.GetVersionRequest) (*adminpb.GetVersionResponse, error) {
+	if err := s.auth.Load().checkGRPC(ctx); err != nil {
+		return nil, err
+	}
+
+	info := buildinfo.Get()
+	return &adminpb.GetVersionResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		Date:      info.Date,
+		Modified:  info.Modified,
+		GoVersion: info.GoVersion,
+	}, nil
+}
+
+// levelChange - запрос на изменение уровня, общий для gRPC и HTTP
+type levelChange struct {
+	Logger string
+	Level  string
+	TTL    time.Duration
+	Reset  bool
+}
+
+func (s *Server) setLevel(ctx context.Context, c levelChange) error {
+	if c.Reset {
+		s.levels.Reset(c.Logger)
+		s.log.InfoContext(ctx, "Уровень логирования сброшен", slog.String("target", c.Logger))
+		return nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return fmt.Errorf("неизвестный уровень логирования %q", c.Level)
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("ttl не может быть отрицательным")
+	}
+	// Ограничение не дает забыть включенный debug в production
+	if s.maxTTL > 0 && (c.TTL == 0 || c.TTL > s.maxTTL) {
+		c.TTL = s.maxTTL
+	}
+
+	s.levels.Set(c.Logger, level, c.TTL)
+	// Ключ logger уже занят именем логгера admin
+	s.log.InfoContext(ctx, "Уровень логирования изменен",
+		slog.String("target", c.Logger),
+		slog.String("level", level.String()),
+		slog.Duration("ttl", c.TTL),
+	)
+	return nil
+}
+
+func toProto(state logger.LevelsState) *adminpb.LogLevels {
+	levels := &adminpb.LogLevels{Default: toLoggerLevel("", state.Default)}
+	for _, name := range state.Names() {
+		levels.Overrides = append(levels.Overrides, toLoggerLevel(name, state.Overrides[name]))
+	}
+	return levels
+}
+
+func toLoggerLevel(name string, o logger.LevelOverride) *adminpb.LoggerLevel {
+	l := &adminpb.LoggerLevel{Logger: name, Level: strings.ToLower(o.Level.String())}
+	if !o.ExpiresAt.IsZero() {
+		l.ExpiresAt = timestamppb.New(o.ExpiresAt)
+	}
+	return l
+}
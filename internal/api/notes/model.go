@@ -13,18 +13,21 @@ type NoteServer struct {
 	usecase usecase.NoteUsecase
 }
 
-func NewServer(log *slog.Logger) *NoteServer {
-	
-	repo := repository.NewPostgresRepo()
-	usecase := usecase.NewBasic(repo)
+func NewServer(log *slog.Logger, repo repository.NoteRepository, limits usecase.LimitsSource) *NoteServer {
+
+	usecase := usecase.NewBasic(repo, limits)
 
 	return &NoteServer{usecase: usecase, log: log}
 }
 
-func (n *NoteServer) AddNote(ctx context.Context, note domain.Note) string {
-	return n.usecase.AddNote(note)
+func (n *NoteServer) AddNote(ctx context.Context, note domain.Note) (string, error) {
+	return n.usecase.AddNote(ctx, note)
+}
+
+func (n *NoteServer) GetNotes(ctx context.Context, userID string) ([]domain.Note, error) {
+	return n.usecase.GetNotes(ctx, userID)
 }
 
-func (n *NoteServer) GetNotes(ctx context.Context, userID string) []domain.Note {
-	return n.usecase.GetNotes(userID)
+func (n *NoteServer) GetUsage(ctx context.Context, userID string) (domain.UsageReport, error) {
+	return n.usecase.GetUsage(ctx, userID)
 }
@@ -1,8 +1,22 @@
 package repository
 
-import "ms_template/internal/domain"
+import (
+	"context"
+	"ms_template/internal/domain"
+)
 
+// NoteRepository хранит заметки. Все запросы выполняются в рамках tenant
+// из контекста; без tenant возвращается tenant.ErrMissing.
 type NoteRepository interface {
-	AddNote(domain.Note) string
-	GetNotes() []domain.Note
+	AddNote(ctx context.Context, note domain.Note) (string, error)
+	GetNotes(ctx context.Context) ([]domain.Note, error)
+	// Usage возвращает потребление пользователя, а при пустом userID - всего tenant
+	Usage(ctx context.Context, userID string) (domain.Usage, error)
+	// Ping проверяет доступность хранилища
+	Ping(ctx context.Context) error
+}
+
+// Migrator применяет миграции схемы хранилища, см. команду migrate
+type Migrator interface {
+	Migrate(ctx context.Context) error
 }
@@ -1,50 +1,140 @@
 package repository
 
 import (
+	"context"
 	"ms_template/internal/domain"
+	"ms_template/internal/tenant"
+	"ms_template/internal/tracing"
 	"sync"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "ms_template/internal/api/notes/repository"
+	collection = "notes"
 )
 
 type Postgres struct {
-	notes map[string]domain.Note
+	notes map[string]map[string]domain.Note // tenant -> id -> заметка
 	mu    *sync.RWMutex
 }
 
-var _ NoteRepository = &Postgres{}
+var (
+	_ NoteRepository = &Postgres{}
+	_ Migrator       = &Postgres{}
+)
 
 func NewPostgresRepo() *Postgres {
 	mu := sync.RWMutex{}
-	notes := make(map[string]domain.Note)
+	notes := make(map[string]map[string]domain.Note)
 	return &Postgres{
 		mu:    &mu,
 		notes: notes,
 	}
 }
 
-func (p *Postgres) GetNotes() []domain.Note {
+func (p *Postgres) GetNotes(ctx context.Context) (_ []domain.Note, err error) {
+	ctx, span := startSpan(ctx, "SELECT")
+	defer func() { tracing.End(span, err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, tenant.ErrMissing
+	}
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	notes := make([]domain.Note, 0, len(p.notes))
+	tenantNotes := p.notes[tenantID]
+	notes := make([]domain.Note, 0, len(tenantNotes))
 
-	for _, note := range p.notes {
+	for _, note := range tenantNotes {
 		notes = append(notes, note)
 	}
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(notes)))
 
-	return notes
+	return notes, nil
 }
 
-func (p *Postgres) AddNote(note domain.Note) string {
+func (p *Postgres) AddNote(ctx context.Context, note domain.Note) (_ string, err error) {
+	ctx, span := startSpan(ctx, "INSERT")
+	defer func() { tracing.End(span, err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", tenant.ErrMissing
+	}
+
 	if note.ID == "" {
 		note.ID = uuid.New().String()
 	}
+	// Tenant всегда берется из контекста, а не из переданной заметки
+	note.TenantID = tenantID
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	
-	
-	p.notes[note.ID] = note
 
-	return note.ID
+	if p.notes[tenantID] == nil {
+		p.notes[tenantID] = make(map[string]domain.Note)
+	}
+	p.notes[tenantID][note.ID] = note
+
+	return note.ID, nil
+}
+
+func (p *Postgres) Usage(ctx context.Context, userID string) (_ domain.Usage, err error) {
+	var attrs []attribute.KeyValue
+	if userID != "" {
+		attrs = append(attrs, semconv.EnduserID(userID))
+	}
+	ctx, span := startSpan(ctx, "SELECT", attrs...)
+	defer func() { tracing.End(span, err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return domain.Usage{}, tenant.ErrMissing
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var usage domain.Usage
+	for _, note := range p.notes[tenantID] {
+		if userID != "" && note.UserID != userID {
+			continue
+		}
+		usage.Notes++
+		usage.Bytes += int64(note.Size())
+	}
+
+	return usage, nil
+}
+
+// Ping всегда успешен: данные хранятся в памяти процесса
+func (p *Postgres) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Migrate приводит схему хранилища к текущей версии. Данные хранятся
+// в памяти процесса, поэтому применять нечего.
+func (p *Postgres) Migrate(ctx context.Context) error {
+	return nil
+}
+
+// startSpan начинает client span запроса к хранилищу с атрибутами БД.
+// Имя span по соглашению OpenTelemetry - "{операция} {коллекция}".
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBCollectionName(collection),
+	)
+	return tracing.Start(ctx, tracerName, operation+" "+collection,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
 }
@@ -1,18 +1,25 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 	
 	"ms_template/internal/domain"
+	"ms_template/internal/tenant"
+	"ms_template/internal/tracing/tracingtest"
 	
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresRepoTestSuite struct {
 	suite.Suite
 	repo *Postgres
+	ctx  context.Context
 }
 
 func TestPostgresRepoTestSuite(t *testing.T) {
@@ -21,6 +28,7 @@ func TestPostgresRepoTestSuite(t *testing.T) {
 
 func (s *PostgresRepoTestSuite) SetupTest() {
 	s.repo = NewPostgresRepo()
+	s.ctx = tenant.NewContext(context.Background(), "tenant-1")
 }
 
 func (s *PostgresRepoTestSuite) TearDownTest() {
@@ -35,7 +43,8 @@ func (s *PostgresRepoTestSuite) TestNewPostgresRepo() {
 
 func (s *PostgresRepoTestSuite) TestGetNotes_EmptyRepository() {
 	// Act
-	notes := s.repo.GetNotes()
+	notes, err := s.repo.GetNotes(s.ctx)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Empty(s.T(), notes)
@@ -53,8 +62,9 @@ func (s *PostgresRepoTestSuite) TestAddNote_SingleNote() {
 	}
 
 	// Act
-	s.repo.AddNote(note)
-	notes := s.repo.GetNotes()
+	s.repo.AddNote(s.ctx, note)
+	notes, err := s.repo.GetNotes(s.ctx)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Len(s.T(), notes, 1)
@@ -92,9 +102,10 @@ func (s *PostgresRepoTestSuite) TestAddNote_MultipleNotes() {
 
 	// Act
 	for _, note := range notesToAdd {
-		s.repo.AddNote(note)
+		s.repo.AddNote(s.ctx, note)
 	}
-	notes := s.repo.GetNotes()
+	notes, err := s.repo.GetNotes(s.ctx)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Len(s.T(), notes, 3)
@@ -133,9 +144,10 @@ func (s *PostgresRepoTestSuite) TestAddNote_UpdateExistingNote() {
 	}
 
 	// Act
-	s.repo.AddNote(initialNote)
-	s.repo.AddNote(updatedNote) // Перезаписываем
-	notes := s.repo.GetNotes()
+	s.repo.AddNote(s.ctx, initialNote)
+	s.repo.AddNote(s.ctx, updatedNote) // Перезаписываем
+	notes, err := s.repo.GetNotes(s.ctx)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Len(s.T(), notes, 1) // Все еще одна запись
@@ -159,7 +171,7 @@ func (s *PostgresRepoTestSuite) TestAddNote_ConcurrentAccess() {
 					Content: "Content",
 					UserID:  "user",
 				}
-				s.repo.AddNote(note)
+				s.repo.AddNote(s.ctx, note)
 			}
 			done <- true
 		}(i)
@@ -171,7 +183,8 @@ func (s *PostgresRepoTestSuite) TestAddNote_ConcurrentAccess() {
 	}
 
 	// Assert
-	notes := s.repo.GetNotes()
+	notes, err := s.repo.GetNotes(s.ctx)
+	require.NoError(s.T(), err)
 	assert.Len(s.T(), notes, numGoroutines*notesPerGoroutine)
 }
 
@@ -193,7 +206,7 @@ func (s *PostgresRepoTestSuite) TestGetNotes_ConcurrentReadWrite() {
 				Content: "Content",
 				UserID:  "user",
 			}
-			s.repo.AddNote(note)
+			s.repo.AddNote(s.ctx, note)
 			time.Sleep(time.Microsecond)
 		}
 		done <- true
@@ -202,7 +215,7 @@ func (s *PostgresRepoTestSuite) TestGetNotes_ConcurrentReadWrite() {
 	// Запускаем горутину для чтения
 	go func() {
 		for i := 0; i < 100; i++ {
-			notes := s.repo.GetNotes()
+			notes, _ := s.repo.GetNotes(s.ctx)
 			_ = len(notes) // Просто читаем
 			time.Sleep(time.Millisecond)
 		}
@@ -213,4 +226,85 @@ func (s *PostgresRepoTestSuite) TestGetNotes_ConcurrentReadWrite() {
 	<-readComplete
 	<-done
 	// Если тест не падает с data race - всё хорошо
-}
\ No newline at end of file
+}
+
+func (s *PostgresRepoTestSuite) TestGetNotes_TenantIsolation() {
+	// Arrange
+	otherCtx := tenant.NewContext(context.Background(), "tenant-2")
+	_, err := s.repo.AddNote(s.ctx, domain.Note{ID: "note-1", Title: "Tenant 1"})
+	require.NoError(s.T(), err)
+	_, err = s.repo.AddNote(otherCtx, domain.Note{ID: "note-2", Title: "Tenant 2", TenantID: "tenant-1"})
+	require.NoError(s.T(), err)
+
+	// Act
+	notes, err := s.repo.GetNotes(s.ctx)
+	require.NoError(s.T(), err)
+	otherNotes, err := s.repo.GetNotes(otherCtx)
+	require.NoError(s.T(), err)
+
+	// Assert
+	assert.Len(s.T(), notes, 1)
+	assert.Equal(s.T(), "note-1", notes[0].ID)
+	assert.Len(s.T(), otherNotes, 1)
+	assert.Equal(s.T(), "note-2", otherNotes[0].ID)
+	// Tenant берется из контекста, а не из заметки
+	assert.Equal(s.T(), "tenant-2", otherNotes[0].TenantID)
+}
+
+func (s *PostgresRepoTestSuite) TestMissingTenant() {
+	// Act
+	_, addErr := s.repo.AddNote(context.Background(), domain.Note{Title: "Note"})
+	_, getErr := s.repo.GetNotes(context.Background())
+
+	// Assert
+	assert.ErrorIs(s.T(), addErr, tenant.ErrMissing)
+	assert.ErrorIs(s.T(), getErr, tenant.ErrMissing)
+}
+
+func (s *PostgresRepoTestSuite) TestUsage() {
+	// Arrange
+	otherCtx := tenant.NewContext(context.Background(), "tenant-2")
+	s.repo.AddNote(s.ctx, domain.Note{ID: "1", Title: "ab", Content: "cde", UserID: "user-1"})
+	s.repo.AddNote(s.ctx, domain.Note{ID: "2", Title: "a", Content: "b", UserID: "user-1"})
+	s.repo.AddNote(s.ctx, domain.Note{ID: "3", Title: "abc", UserID: "user-2"})
+	s.repo.AddNote(otherCtx, domain.Note{ID: "4", Title: "abcdef", UserID: "user-1"})
+
+	// Act
+	userUsage, err := s.repo.Usage(s.ctx, "user-1")
+	require.NoError(s.T(), err)
+	tenantUsage, err := s.repo.Usage(s.ctx, "")
+	require.NoError(s.T(), err)
+
+	// Assert
+	assert.Equal(s.T(), domain.Usage{Notes: 2, Bytes: 7}, userUsage)
+	assert.Equal(s.T(), domain.Usage{Notes: 3, Bytes: 10}, tenantUsage)
+}
+
+func (s *PostgresRepoTestSuite) TestSpans() {
+	// Arrange
+	recorder := tracingtest.Install(s.T())
+
+	// Act
+	s.repo.AddNote(s.ctx, domain.Note{Title: "Note", UserID: "user-1"})
+	s.repo.GetNotes(s.ctx)
+	s.repo.GetNotes(context.Background())
+
+	// Assert
+	spans := recorder.Ended()
+	require.Len(s.T(), spans, 3)
+
+	insert := spans[0]
+	assert.Equal(s.T(), "INSERT notes", insert.Name())
+	assert.Equal(s.T(), trace.SpanKindClient, insert.SpanKind())
+	attrs := tracingtest.Attributes(insert)
+	assert.Equal(s.T(), "postgresql", attrs["db.system.name"].AsString())
+	assert.Equal(s.T(), "INSERT", attrs["db.operation.name"].AsString())
+	assert.Equal(s.T(), "notes", attrs["db.collection.name"].AsString())
+	assert.Equal(s.T(), "tenant-1", attrs["tenant.id"].AsString())
+
+	assert.Equal(s.T(), "SELECT notes", spans[1].Name())
+	assert.Equal(s.T(), int64(1), tracingtest.Attributes(spans[1])["db.response.returned_rows"].AsInt64())
+
+	// Запрос без tenant завершается ошибкой в span
+	assert.Equal(s.T(), codes.Error, spans[2].Status().Code)
+}
@@ -1,30 +1,202 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
 	"ms_template/internal/api/notes/repository"
+	"ms_template/internal/config"
 	"ms_template/internal/domain"
+	"ms_template/internal/tenant"
+	"ms_template/internal/tracing"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "ms_template/internal/api/notes/usecase"
+
+// FeatureTenantUsage - флаг features, при котором GetUsage возвращает
+// потребление и квоту всего tenant, а не только пользователя
+const FeatureTenantUsage = "tenant_usage"
 
 type Basic struct {
-	repo 	repository.NoteRepository
+	repo   repository.NoteRepository
+	limits LimitsSource // nil - без ограничений
+	// quotaLocks сериализует проверку квот и вставку заметки в пределах
+	// tenant: иначе параллельные вызовы пройдут проверку по одному и тому же
+	// потреблению и вместе превысят квоту. Блокировка действует в пределах
+	// процесса, общему хранилищу нескольких реплик нужна проверка в транзакции.
+	quotaLocks keyedMutex
 }
 
 var _ NoteUsecase = &Basic{}
 
-func NewBasic(repo repository.NoteRepository) *Basic {
-	return &Basic{repo: repo}
+func NewBasic(repo repository.NoteRepository, limits LimitsSource) *Basic {
+	return &Basic{repo: repo, limits: limits}
 }
 
-func (b *Basic) GetNotes(userID string) []domain.Note {
-	return b.repo.GetNotes()
+func (b *Basic) GetNotes(ctx context.Context, userID string) (notes []domain.Note, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "NoteUsecase.GetNotes", trace.WithAttributes(semconv.EnduserID(userID)))
+	defer func() { tracing.End(span, err) }()
+
+	notes, err = b.repo.GetNotes(ctx)
+	span.SetAttributes(attribute.Int("notes.count", len(notes)))
+	return notes, err
 }
 
-func (b *Basic) AddNote(note domain.Note) string {
+func (b *Basic) AddNote(ctx context.Context, note domain.Note) (id string, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "NoteUsecase.AddNote", trace.WithAttributes(
+		semconv.EnduserID(note.UserID),
+		attribute.Int("note.size", note.Size()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if b.limits != nil {
+		tenantID, _ := tenant.FromContext(ctx)
+		unlock := b.quotaLocks.lock(tenantID)
+		defer unlock()
+	}
+	if err := b.checkQuota(ctx, note); err != nil {
+		return "", err
+	}
+
 	note.ID = uuid.New().String()
 	note.CreatedAt = time.Now()
-	return b.repo.AddNote(note)
-}
\ No newline at end of file
+	span.SetAttributes(attribute.String("note.id", note.ID))
+	return b.repo.AddNote(ctx, note)
+}
+
+func (b *Basic) GetUsage(ctx context.Context, userID string) (_ domain.UsageReport, err error) {
+	ctx, span := tracing.Start(ctx, tracerName, "NoteUsecase.GetUsage", trace.WithAttributes(semconv.EnduserID(userID)))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := b.repo.Usage(ctx, userID)
+	if err != nil {
+		return domain.UsageReport{}, err
+	}
+	report := domain.UsageReport{User: user}
+
+	// Без источника настроек ограничений нет, и данные tenant выдаются всегда
+	report.TenantIncluded = b.limits == nil || b.limits.FeatureEnabled(ctx, FeatureTenantUsage)
+	if report.TenantIncluded {
+		if report.Tenant, err = b.repo.Usage(ctx, ""); err != nil {
+			return domain.UsageReport{}, err
+		}
+	}
+
+	if b.limits != nil {
+		tenantID, _ := tenant.FromContext(ctx)
+		report.UserQuota = toQuota(b.limits.UserLimits(tenantID, userID))
+		if report.TenantIncluded {
+			report.TenantQuota = toQuota(b.limits.TenantQuota(tenantID))
+		}
+	}
+
+	return report, nil
+}
+
+// checkQuota проверяет, что новая заметка не превысит квоты
+// пользователя и tenant. Возвращает *domain.QuotaError со всеми нарушениями.
+func (b *Basic) checkQuota(ctx context.Context, note domain.Note) error {
+	if b.limits == nil {
+		return nil
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	size := int64(note.Size())
+	userSubject := "user:" + note.UserID
+	tenantSubject := "tenant:" + tenantID
+
+	var violations []domain.QuotaViolation
+
+	userLimits := b.limits.UserLimits(tenantID, note.UserID)
+	if userLimits.MaxNoteSize != nil && size > int64(*userLimits.MaxNoteSize) {
+		violations = append(violations, domain.QuotaViolation{
+			Subject:     userSubject,
+			Description: fmt.Sprintf("размер заметки %d байт превышает лимит %d байт", size, *userLimits.MaxNoteSize),
+		})
+	}
+
+	if userLimits.MaxNotes != nil || userLimits.MaxBytes != nil {
+		usage, err := b.repo.Usage(ctx, note.UserID)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, usageViolations(userSubject, usage, size, userLimits)...)
+	}
+
+	tenantQuota := b.limits.TenantQuota(tenantID)
+	if tenantQuota.MaxNotes != nil || tenantQuota.MaxBytes != nil {
+		usage, err := b.repo.Usage(ctx, "")
+		if err != nil {
+			return err
+		}
+		violations = append(violations, usageViolations(tenantSubject, usage, size, tenantQuota)...)
+	}
+
+	if len(violations) > 0 {
+		return &domain.QuotaError{Violations: violations}
+	}
+	return nil
+}
+
+func usageViolations(subject string, usage domain.Usage, size int64, limits config.LimitsConfig) []domain.QuotaViolation {
+	var violations []domain.QuotaViolation
+
+	if limits.MaxNotes != nil && usage.Notes+1 > int64(*limits.MaxNotes) {
+		violations = append(violations, domain.QuotaViolation{
+			Subject:     subject,
+			Description: fmt.Sprintf("достигнут лимит числа заметок %d", *limits.MaxNotes),
+		})
+	}
+	if limits.MaxBytes != nil && usage.Bytes+size > *limits.MaxBytes {
+		violations = append(violations, domain.QuotaViolation{
+			Subject:     subject,
+			Description: fmt.Sprintf("суммарный размер заметок превысит лимит %d байт", *limits.MaxBytes),
+		})
+	}
+
+	return violations
+}
+
+func toQuota(l config.LimitsConfig) domain.Quota {
+	var q domain.Quota
+	if l.MaxNotes != nil {
+		q.MaxNotes = int64(*l.MaxNotes)
+	}
+	if l.MaxBytes != nil {
+		q.MaxBytes = *l.MaxBytes
+	}
+	if l.MaxNoteSize != nil {
+		q.MaxNoteSize = int64(*l.MaxNoteSize)
+	}
+	return q
+}
+
+// keyedMutex - набор мьютексов по ключу. Мьютексы не удаляются:
+// ключами служат tenant, их число ограничено.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock захватывает мьютекс key и возвращает функцию освобождения
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*sync.Mutex)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		k.locks[key] = l
+	}
+	k.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
@@ -1,8 +1,20 @@
 package usecase
 
-import "ms_template/internal/domain"
+import (
+	"context"
+	"ms_template/internal/config"
+	"ms_template/internal/domain"
+)
 
 type NoteUsecase interface {
-	AddNote(domain.Note) string
-	GetNotes(userID string) []domain.Note
-}
\ No newline at end of file
+	AddNote(ctx context.Context, note domain.Note) (string, error)
+	GetNotes(ctx context.Context, userID string) ([]domain.Note, error)
+	GetUsage(ctx context.Context, userID string) (domain.UsageReport, error)
+}
+
+// LimitsSource возвращает квоты хранения и флаги tenant
+type LimitsSource interface {
+	UserLimits(tenantID, userID string) config.LimitsConfig
+	TenantQuota(tenantID string) config.LimitsConfig
+	FeatureEnabled(ctx context.Context, feature string) bool
+}
@@ -1,15 +1,23 @@
 package usecase
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"ms_template/internal/api/notes/repository"
+	"ms_template/internal/config"
 	"ms_template/internal/domain"
+	"ms_template/internal/tenant"
+	"ms_template/internal/tracing/tracingtest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Мок репозитория для тестирования юзкейса
@@ -17,20 +25,49 @@ type MockNoteRepository struct {
 	mock.Mock
 }
 
-func (m *MockNoteRepository) AddNote(note domain.Note) string {
+func (m *MockNoteRepository) AddNote(ctx context.Context, note domain.Note) (string, error) {
 	args := m.Called(note)
-	return args.String(0)
+	return args.String(0), nil
 }
 
-func (m *MockNoteRepository) GetNotes() []domain.Note {
+func (m *MockNoteRepository) GetNotes(ctx context.Context) ([]domain.Note, error) {
 	args := m.Called()
-	return args.Get(0).([]domain.Note)
+	return args.Get(0).([]domain.Note), nil
+}
+
+func (m *MockNoteRepository) Usage(ctx context.Context, userID string) (domain.Usage, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.Usage), nil
+}
+
+func (m *MockNoteRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Фиксированные квоты и флаги для всех пользователей и tenant
+type staticLimits struct {
+	user     config.LimitsConfig
+	tenant   config.LimitsConfig
+	features map[string]bool
+}
+
+func (l staticLimits) UserLimits(string, string) config.LimitsConfig {
+	return l.user
+}
+
+func (l staticLimits) TenantQuota(string) config.LimitsConfig {
+	return l.tenant
+}
+
+func (l staticLimits) FeatureEnabled(_ context.Context, feature string) bool {
+	return l.features[feature]
 }
 
 type BasicUsecaseTestSuite struct {
 	suite.Suite
 	mockRepo *MockNoteRepository
 	usecase  NoteUsecase
+	ctx      context.Context
 }
 
 func TestBasicUsecaseTestSuite(t *testing.T) {
@@ -39,7 +76,8 @@ func TestBasicUsecaseTestSuite(t *testing.T) {
 
 func (s *BasicUsecaseTestSuite) SetupTest() {
 	s.mockRepo = new(MockNoteRepository)
-	s.usecase = NewBasic(s.mockRepo)
+	s.usecase = NewBasic(s.mockRepo, nil)
+	s.ctx = tenant.NewContext(context.Background(), "tenant-1")
 }
 
 func (s *BasicUsecaseTestSuite) TestNewBasic() {
@@ -47,7 +85,7 @@ func (s *BasicUsecaseTestSuite) TestNewBasic() {
 	repo := new(MockNoteRepository)
 
 	// Act
-	usecaseInstance := NewBasic(repo)
+	usecaseInstance := NewBasic(repo, nil)
 
 	// Assert
 	assert.NotNil(s.T(), usecaseInstance)
@@ -76,7 +114,8 @@ func (s *BasicUsecaseTestSuite) TestGetNotes() {
 	s.mockRepo.On("GetNotes").Return(expectedNotes)
 
 	// Act
-	notes := s.usecase.GetNotes("user-1")
+	notes, err := s.usecase.GetNotes(s.ctx, "user-1")
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Len(s.T(), notes, 2)
@@ -90,7 +129,8 @@ func (s *BasicUsecaseTestSuite) TestGetNotes_EmptyResult() {
 	s.mockRepo.On("GetNotes").Return([]domain.Note{})
 
 	// Act
-	notes := s.usecase.GetNotes("user-1")
+	notes, err := s.usecase.GetNotes(s.ctx, "user-1")
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Empty(s.T(), notes)
@@ -120,7 +160,8 @@ func (s *BasicUsecaseTestSuite) TestAddNote() {
 		})
 
 	// Act
-	resultID := s.usecase.AddNote(note)
+	resultID, err := s.usecase.AddNote(s.ctx, note)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.NotEmpty(s.T(), resultID)
@@ -150,7 +191,8 @@ func (s *BasicUsecaseTestSuite) TestAddNote_WithExistingID() {
 	})).Return("new-id")
 
 	// Act
-	resultID := s.usecase.AddNote(note)
+	resultID, err := s.usecase.AddNote(s.ctx, note)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.NotEmpty(s.T(), resultID)
@@ -184,7 +226,8 @@ func (s *BasicUsecaseTestSuite) TestAddNote_WithDifferentUsers() {
 
 	// Act & Assert
 	for _, note := range notes {
-		resultID := s.usecase.AddNote(note)
+		resultID, err := s.usecase.AddNote(s.ctx, note)
+		require.NoError(s.T(), err)
 		assert.NotEmpty(s.T(), resultID)
 	}
 
@@ -202,9 +245,12 @@ func (s *BasicUsecaseTestSuite) TestGetNotes_UserIDParameterIgnored() {
 	s.mockRepo.On("GetNotes").Return(allNotes).Times(3)
 
 	// Act
-	notes1 := s.usecase.GetNotes("user-1")
-	notes2 := s.usecase.GetNotes("user-2")
-	notes3 := s.usecase.GetNotes("")
+	notes1, err := s.usecase.GetNotes(s.ctx, "user-1")
+	require.NoError(s.T(), err)
+	notes2, err := s.usecase.GetNotes(s.ctx, "user-2")
+	require.NoError(s.T(), err)
+	notes3, err := s.usecase.GetNotes(s.ctx, "")
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Len(s.T(), notes1, 3)
@@ -257,7 +303,8 @@ func (s *BasicUsecaseTestSuite) TestAddNote_EmptyFields() {
 			})).Return("generated-id")
 
 			// Act
-			resultID := s.usecase.AddNote(tc.note)
+			resultID, err := s.usecase.AddNote(s.ctx, tc.note)
+			require.NoError(t, err)
 
 			// Assert
 			assert.NotEmpty(t, resultID)
@@ -279,9 +326,198 @@ func (s *BasicUsecaseTestSuite) TestAddNote_ReturnsGeneratedID() {
 	s.mockRepo.On("AddNote", mock.AnythingOfType("domain.Note")).Return(expectedID)
 
 	// Act
-	resultID := s.usecase.AddNote(note)
+	resultID, err := s.usecase.AddNote(s.ctx, note)
+	require.NoError(s.T(), err)
 
 	// Assert
 	assert.Equal(s.T(), expectedID, resultID)
 	s.mockRepo.AssertExpectations(s.T())
-}
\ No newline at end of file
+}
+func (s *BasicUsecaseTestSuite) TestAddNote_ExceedsMaxNoteSize() {
+	// Arrange
+	maxSize := 8
+	uc := NewBasic(s.mockRepo, staticLimits{user: config.LimitsConfig{MaxNoteSize: &maxSize}})
+	note := domain.Note{
+		Title:   "Title",
+		Content: "Too long content",
+		UserID:  "user-1",
+	}
+
+	// Act
+	_, err := uc.AddNote(s.ctx, note)
+
+	// Assert
+	var quotaErr *domain.QuotaError
+	require.ErrorAs(s.T(), err, &quotaErr)
+	require.Len(s.T(), quotaErr.Violations, 1)
+	assert.Equal(s.T(), "user:user-1", quotaErr.Violations[0].Subject)
+	s.mockRepo.AssertNotCalled(s.T(), "AddNote", mock.Anything)
+}
+
+func (s *BasicUsecaseTestSuite) TestAddNote_ExceedsUserAndTenantQuota() {
+	// Arrange
+	maxNotes := 2
+	maxBytes := int64(100)
+	uc := NewBasic(s.mockRepo, staticLimits{
+		user:   config.LimitsConfig{MaxNotes: &maxNotes},
+		tenant: config.LimitsConfig{MaxBytes: &maxBytes},
+	})
+	s.mockRepo.On("Usage", "user-1").Return(domain.Usage{Notes: 2, Bytes: 20})
+	s.mockRepo.On("Usage", "").Return(domain.Usage{Notes: 10, Bytes: 95})
+
+	// Act
+	_, err := uc.AddNote(s.ctx, domain.Note{Title: "Title", Content: "Content", UserID: "user-1"})
+
+	// Assert
+	var quotaErr *domain.QuotaError
+	require.ErrorAs(s.T(), err, &quotaErr)
+	require.Len(s.T(), quotaErr.Violations, 2)
+	assert.Equal(s.T(), "user:user-1", quotaErr.Violations[0].Subject)
+	assert.Equal(s.T(), "tenant:tenant-1", quotaErr.Violations[1].Subject)
+	s.mockRepo.AssertNotCalled(s.T(), "AddNote", mock.Anything)
+}
+
+func (s *BasicUsecaseTestSuite) TestAddNote_WithinQuota() {
+	// Arrange
+	maxNotes := 2
+	uc := NewBasic(s.mockRepo, staticLimits{user: config.LimitsConfig{MaxNotes: &maxNotes}})
+	s.mockRepo.On("Usage", "user-1").Return(domain.Usage{Notes: 1, Bytes: 20})
+	s.mockRepo.On("AddNote", mock.AnythingOfType("domain.Note")).Return("generated-id")
+
+	// Act
+	id, err := uc.AddNote(s.ctx, domain.Note{Title: "Title", UserID: "user-1"})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "generated-id", id)
+	s.mockRepo.AssertExpectations(s.T())
+}
+
+func (s *BasicUsecaseTestSuite) TestGetUsage() {
+	// Arrange
+	maxNotes := 10
+	maxBytes := int64(1000)
+	uc := NewBasic(s.mockRepo, staticLimits{
+		user:     config.LimitsConfig{MaxNotes: &maxNotes},
+		tenant:   config.LimitsConfig{MaxBytes: &maxBytes},
+		features: map[string]bool{FeatureTenantUsage: true},
+	})
+	s.mockRepo.On("Usage", "user-1").Return(domain.Usage{Notes: 3, Bytes: 30})
+	s.mockRepo.On("Usage", "").Return(domain.Usage{Notes: 7, Bytes: 70})
+
+	// Act
+	report, err := uc.GetUsage(s.ctx, "user-1")
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.Usage{Notes: 3, Bytes: 30}, report.User)
+	assert.Equal(s.T(), domain.Usage{Notes: 7, Bytes: 70}, report.Tenant)
+	assert.Equal(s.T(), int64(10), report.UserQuota.MaxNotes)
+	assert.Equal(s.T(), int64(1000), report.TenantQuota.MaxBytes)
+	assert.Zero(s.T(), report.UserQuota.MaxBytes)
+	assert.True(s.T(), report.TenantIncluded)
+}
+
+func (s *BasicUsecaseTestSuite) TestGetUsage_TenantUsageDisabled() {
+	// Arrange
+	maxBytes := int64(1000)
+	uc := NewBasic(s.mockRepo, staticLimits{tenant: config.LimitsConfig{MaxBytes: &maxBytes}})
+	s.mockRepo.On("Usage", "user-1").Return(domain.Usage{Notes: 3, Bytes: 30})
+
+	// Act
+	report, err := uc.GetUsage(s.ctx, "user-1")
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.Usage{Notes: 3, Bytes: 30}, report.User)
+	assert.False(s.T(), report.TenantIncluded)
+	assert.Zero(s.T(), report.Tenant)
+	assert.Zero(s.T(), report.TenantQuota)
+	s.mockRepo.AssertNotCalled(s.T(), "Usage", "")
+}
+
+func (s *BasicUsecaseTestSuite) TestAddNote_RecordsSpan() {
+	// Arrange
+	recorder := tracingtest.Install(s.T())
+	s.mockRepo.On("AddNote", mock.AnythingOfType("domain.Note")).Return("generated-id")
+
+	// Act
+	_, err := s.usecase.AddNote(s.ctx, domain.Note{Title: "Title", Content: "Content", UserID: "user-1"})
+	require.NoError(s.T(), err)
+
+	// Assert
+	span := tracingtest.Span(recorder, "NoteUsecase.AddNote")
+	require.NotNil(s.T(), span)
+	attrs := tracingtest.Attributes(span)
+	assert.Equal(s.T(), "user-1", attrs["enduser.id"].AsString())
+	assert.Equal(s.T(), "tenant-1", attrs["tenant.id"].AsString())
+	assert.Equal(s.T(), int64(12), attrs["note.size"].AsInt64())
+	assert.Equal(s.T(), codes.Unset, span.Status().Code)
+}
+
+func (s *BasicUsecaseTestSuite) TestAddNote_QuotaErrorMarksSpan() {
+	// Arrange
+	recorder := tracingtest.Install(s.T())
+	maxSize := 1
+	uc := NewBasic(s.mockRepo, staticLimits{user: config.LimitsConfig{MaxNoteSize: &maxSize}})
+
+	// Act
+	_, err := uc.AddNote(s.ctx, domain.Note{Title: "Title", UserID: "user-1"})
+	require.Error(s.T(), err)
+
+	// Assert
+	span := tracingtest.Span(recorder, "NoteUsecase.AddNote")
+	require.NotNil(s.T(), span)
+	assert.Equal(s.T(), codes.Error, span.Status().Code)
+	require.Len(s.T(), span.Events(), 1)
+	assert.Equal(s.T(), "exception", span.Events()[0].Name)
+}
+
+func (s *BasicUsecaseTestSuite) TestAddNote_ConcurrentQuota() {
+	// Arrange
+	const workers = 20
+	maxNotes := 1
+	repo := slowUsageRepo{Postgres: repository.NewPostgresRepo()}
+	uc := NewBasic(repo, staticLimits{user: config.LimitsConfig{MaxNotes: &maxNotes}})
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	// Act
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.AddNote(s.ctx, domain.Note{Title: "Title", UserID: "user-1"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var quotaErr *domain.QuotaError
+		assert.ErrorAs(s.T(), err, &quotaErr)
+	}
+	assert.Equal(s.T(), 1, succeeded)
+	usage, err := repo.Usage(s.ctx, "user-1")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), usage.Notes)
+}
+
+// slowUsageRepo замедляет чтение потребления, чтобы параллельные вызовы
+// гарантированно пересекались между проверкой квоты и вставкой
+type slowUsageRepo struct {
+	*repository.Postgres
+}
+
+func (r slowUsageRepo) Usage(ctx context.Context, userID string) (domain.Usage, error) {
+	usage, err := r.Postgres.Usage(ctx, userID)
+	time.Sleep(5 * time.Millisecond)
+	return usage, err
+}
@@ -2,114 +2,315 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"time"
 
+	adminpb "ms_template/gen/go/admin"
+	notespb "ms_template/gen/go/notes"
+	"ms_template/internal/admin"
 	"ms_template/internal/api/notes"
+	"ms_template/internal/api/notes/repository"
 	"ms_template/internal/config"
 	grpcserver "ms_template/internal/grpc"
-
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"google.golang.org/grpc"
+	"ms_template/internal/health"
+	"ms_template/internal/lifecycle"
+	"ms_template/internal/logger"
+	metrics "ms_template/internal/metric"
+	"ms_template/internal/tenant"
+	"ms_template/internal/tracing"
 )
 
 type App struct {
-	log         *slog.Logger
-	cfg         *config.Config
-	grpcServer  *grpcserver.App
-	httpServer  *http.Server
-	port        int
+	log        *slog.Logger
+	cfg        *config.Config
+	grpcServer *grpcserver.App
+	httpServer *http.Server
+	// adminServer обслуживает /admin/loglevel на отдельном адресе
+	// admin.http_addr; nil, если admin.enabled выключен
+	adminServer *http.Server
+	health      *health.Registry
+	metrics     *metrics.Metrics
+	tracing     *tracing.Provider
+	admin       *admin.Server // nil, если admin.enabled выключен
+	tenants     *tenant.Registry
+	levels      *logger.Levels
+	// logLevel - logging.level последнего примененного снимка конфигурации
+	logLevel    string
+	probes      *probes
+	lifecycle   *lifecycle.Manager
 	metricsPort int
 }
 
-func New(log *slog.Logger, cfg *config.Config) *App {
-	server := notes.NewServer(log)
-	grpcServer := grpcserver.New(log, server, *cfg.GRPC.Port, *cfg.Prometheus.Port)
-	
-	return &App{
+func New(log *slog.Logger, levels *logger.Levels, cfg *config.Config) (*App, error) {
+	// Трассировка настраивается первой: она устанавливает глобальный провайдер,
+	// который используют gRPC сервер и слои приложения
+	tracer, err := tracing.New(context.Background(), log, cfg.Tracing, cfg.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := repository.NewPostgresRepo()
+	tenants := tenant.NewRegistry(cfg)
+	server := notes.NewServer(log, repo, tenants)
+
+	checks := health.NewRegistry(logger.Named(log, "health"), cfg.Health.Interval, cfg.Health.Timeout)
+	checks.Register("repository", health.CheckerFunc(repo.Ping), notespb.Notes_ServiceDesc.ServiceName)
+
+	// Один регистр на все подсистемы: gRPC, HTTP и кастомные метрики
+	// публикуются на общем /metrics
+	m := metrics.New("notes_service", metrics.Options{
+		TenantLabel: cfg.Prometheus.TenantLabel,
+		MaxTenants:  cfg.Prometheus.MaxTenantLabels,
+	})
+
+	grpcServer, err := grpcserver.New(log, server, cfg, checks, m)
+	if err != nil {
+		tracer.Shutdown(context.Background())
+		return nil, err
+	}
+
+	a := &App{
 		log:         log,
 		cfg:         cfg,
 		grpcServer:  grpcServer,
-		port:        *cfg.GRPC.Port,
-		httpServer: &http.Server{},
+		health:      checks,
+		metrics:     m,
+		tracing:     tracer,
+		tenants:     tenants,
+		levels:      levels,
+		logLevel:    cfg.Logging.Level,
+		probes:      &probes{health: checks},
+		lifecycle:   lifecycle.New(logger.Named(log, "lifecycle"), cfg.Shutdown.Timeout),
 		metricsPort: *cfg.Prometheus.Port,
 	}
+	if cfg.Admin.Enabled {
+		a.admin = admin.NewServer(logger.Named(log, "admin"), levels, cfg.Admin)
+		grpcServer.RegisterService(&adminpb.Admin_ServiceDesc, a.admin)
+	}
+
+	a.httpServer = a.newMetricsServer()
+	if a.admin != nil {
+		a.adminServer = a.newAdminServer()
+	}
+	a.registerComponents()
+
+	return a, nil
 }
 
-func (a *App) Run() error {
-	// Запуск gRPC сервера
-	go func() {
-		if err := a.runGRPCServer(); err != nil {
-			a.log.Error("Ошибка gRPC сервера", "error", err)
-		}
-	}()
+// Run запускает компоненты и блокируется до отмены ctx или сбоя
+// одного из них, после чего останавливает приложение.
+// Ошибка запуска (например, занятый порт) возвращается сразу.
+func (a *App) Run(ctx context.Context) error {
+	return a.lifecycle.Run(ctx)
+}
 
-	// Запуск HTTP сервера для метрик
-	go func() {
-		if err := a.runMetricsServer(); err != nil && err != http.ErrServerClosed {
-			a.log.Error("Ошибка метрик сервера", "error", err)
-		}
-	}()
+// WatchConfig включает перезагрузку конфигурации из opts по SIGHUP
+// и при изменении файла. Вызывается до Run.
+func (a *App) WatchConfig(opts config.LoadOptions) {
+	watcher := config.NewWatcher(logger.Named(a.log, "config"), opts, a.cfg, a.metrics)
+	watcher.Subscribe(a.applyConfig)
 
-	// Блокируем основную горутину
-	select {}
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	a.lifecycle.Add(lifecycle.Component{
+		Name:      "config",
+		DependsOn: []string{"readiness"},
+		Start: func(ctx context.Context) error {
+			go watcher.Run(watchCtx)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stopWatch()
+			return nil
+		},
+	})
 }
 
-func (a *App) runGRPCServer() error {
+// applyConfig применяет перезагружаемые поля новой конфигурации:
+// лимиты и флаги tenant, правила rate limit, секреты и уровень логирования.
+// Уровень применяется только при изменении logging.level, чтобы перезагрузка
+// из-за других полей, например обновления секретов, не сбрасывала уровень,
+// заданный через admin.
+func (a *App) applyConfig(cfg *config.Config) {
+	a.tenants.Update(cfg)
+	a.grpcServer.UpdateConfig(cfg)
+	if a.admin != nil {
+		a.admin.UpdateConfig(cfg.Admin)
+	}
 
-	if err := a.grpcServer.Run(); err != nil && err != grpc.ErrServerStopped {
-		return fmt.Errorf("ошибка обслуживания grpc сервера: %w", err)
+	if cfg.Logging.Level == a.logLevel {
+		return
 	}
+	a.logLevel = cfg.Logging.Level
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Logging.Level)); err == nil {
+		a.levels.SetBase(level)
+	}
+}
 
-	return nil
+// registerComponents описывает порядок запуска: проверки зависимостей,
+// затем серверы, и в конце отметка готовности. Остановка идет в обратном
+// порядке, поэтому готовность снимается раньше остановки серверов.
+func (a *App) registerComponents() {
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+
+	// Трассировка останавливается последней, чтобы отправить span
+	// запросов, завершившихся во время остановки серверов
+	a.lifecycle.Add(lifecycle.Component{
+		Name: "tracing",
+		Stop: a.tracing.Shutdown,
+	})
+
+	a.lifecycle.Add(lifecycle.Component{
+		Name:      "health",
+		DependsOn: []string{"tracing"},
+		Start: func(ctx context.Context) error {
+			// Run выполняет первую проверку сразу, далее каждые health.interval
+			go a.health.Run(healthCtx)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stopHealth()
+			return nil
+		},
+	})
+
+	a.lifecycle.Add(a.httpComponent("metrics-http", a.httpServer, "health"))
+	readinessDeps := []string{"grpc", "metrics-http"}
+	if a.adminServer != nil {
+		a.lifecycle.Add(a.httpComponent("admin-http", a.adminServer, "health"))
+		readinessDeps = append(readinessDeps, "admin-http")
+	}
+
+	a.lifecycle.Add(lifecycle.Component{
+		Name:      "grpc",
+		DependsOn: []string{"health"},
+		Start: func(ctx context.Context) error {
+			return a.grpcServer.Listen()
+		},
+		Serve: a.grpcServer.Serve,
+		Stop: func(ctx context.Context) error {
+			a.stopGRPCServer(ctx)
+			return nil
+		},
+	})
+
+	a.lifecycle.Add(lifecycle.Component{
+		Name:      "readiness",
+		DependsOn: readinessDeps,
+		Start: func(ctx context.Context) error {
+			a.probes.started.Store(true)
+			return nil
+		},
+		Stop:        a.drain,
+		StopTimeout: a.cfg.Shutdown.DrainDelay + time.Second,
+	})
 }
 
-func (a *App) runMetricsServer() error {
+func (a *App) newMetricsServer() *http.Server {
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
-	mux.Handle("/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}))
+	mux.Handle("/metrics", a.metrics.InstrumentHandler("/metrics", a.metrics.Handler()))
+	mux.Handle("/health", a.metrics.InstrumentHandler("/health", a.health.Handler()))
+	a.probes.register(mux, a.metrics.InstrumentHandler)
 
-	a.httpServer = &http.Server{
+	return &http.Server{
 		Addr:    fmt.Sprintf(":%d", a.metricsPort),
 		Handler: mux,
 	}
+}
 
-	a.log.Info("Metrics server started", "port", a.metricsPort)
-	return a.httpServer.ListenAndServe()
+// newAdminServer создает HTTP сервер административных эндпоинтов. Токен
+// передается в открытом виде, поэтому сервер слушает только loopback
+// адрес admin.http_addr, доступный, например, через kubectl port-forward.
+func (a *App) newAdminServer() *http.Server {
+	mux := http.NewServeMux()
+	a.admin.Register(mux)
+
+	return &http.Server{
+		Addr:    a.cfg.Admin.HTTPAddr,
+		Handler: mux,
+	}
 }
 
-func (a *App) Shutdown(ctx context.Context) error {
-	a.log.Info("Начало graceful shutdown...")
-	
-	// Останавливаем gRPC сервер
-	if a.grpcServer != nil {
-		a.log.Info("Остановка gRPC сервера...")
-		a.grpcServer.GracefulStop()
-		a.log.Info("gRPC сервер остановлен")
+// httpComponent описывает HTTP сервер: Start привязывает листенер, чтобы
+// занятый порт обнаруживался до объявления готовности, Serve обслуживает
+// его, Stop дожидается текущих запросов
+func (a *App) httpComponent(name string, srv *http.Server, dependsOn ...string) lifecycle.Component {
+	var listener net.Listener
+
+	return lifecycle.Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(ctx context.Context) error {
+			l, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return fmt.Errorf("ошибка прослушивания адреса %s: %w", srv.Addr, err)
+			}
+			listener = l
+
+			a.log.Info("HTTP server started", "component", name, "addr", l.Addr().String())
+			return nil
+		},
+		Serve: func() error {
+			if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("ошибка HTTP сервера %s: %w", name, err)
+			}
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			if err := srv.Shutdown(ctx); err != nil {
+				return fmt.Errorf("ошибка остановки HTTP сервера %s: %w", name, err)
+			}
+			// Shutdown закрывает только листенеры, переданные в Serve. Если
+			// запуск прервался раньше, листенер закрывается здесь.
+			if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				return fmt.Errorf("ошибка закрытия листенера %s: %w", name, err)
+			}
+			return nil
+		},
 	}
-	
-	// Останавливаем HTTP сервер
-	if a.httpServer != nil {
-		a.log.Info("Остановка HTTP сервера метрик...")
-		if err := a.httpServer.Shutdown(ctx); err != nil {
-			return fmt.Errorf("ошибка остановки HTTP сервера: %w", err)
+}
+
+// drain снимает готовность и ждет, пока балансировщики и kube-proxy
+// перестанут направлять трафик
+func (a *App) drain(ctx context.Context) error {
+	a.log.Info("Начало graceful shutdown...")
+
+	a.probes.draining.Store(true)
+	a.health.Shutdown()
+
+	if delay := a.cfg.Shutdown.DrainDelay; delay > 0 {
+		a.log.Info("Ожидание drain перед остановкой", "drain_delay", delay.String())
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
 		}
-		a.log.Info("HTTP сервер метрик остановлен")
 	}
-	
 	return nil
 }
 
+// stopGRPCServer дожидается завершения текущих вызовов, а по истечении
+// ctx обрывает оставшиеся
+func (a *App) stopGRPCServer(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		a.log.Warn("Таймаут graceful stop gRPC сервера, соединения закрываются принудительно")
+		a.grpcServer.Stop()
+		<-done
+	}
+}
+
 // GRPCServer возвращает gRPC сервер (для совместимости со старым кодом)
 func (a *App) GRPCServer() *grpcserver.App {
 	return a.grpcServer
 }
-
-// RunMetricsServer запускает сервер метрик (публичный метод)
-func (a *App) RunMetricsServer() error {
-	return a.runMetricsServer()
-}
\ No newline at end of file
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"ms_template/internal/health"
+)
+
+// probes отвечает на пробы Kubernetes:
+// livez - процесс жив, startupz - запуск завершен,
+// readyz - зависимости доступны и сервис не завершается
+type probes struct {
+	health   *health.Registry
+	started  atomic.Bool
+	draining atomic.Bool
+}
+
+// register добавляет пробы в mux; instrument оборачивает обработчик метриками
+func (p *probes) register(mux *http.ServeMux, instrument func(string, http.Handler) http.Handler) {
+	handle := func(path string, ok func() bool) {
+		mux.Handle(path, instrument(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeProbe(w, ok())
+		})))
+	}
+
+	handle("/livez", func() bool { return true })
+	handle("/startupz", p.started.Load)
+	handle("/readyz", p.ready)
+}
+
+func (p *probes) ready() bool {
+	return p.started.Load() && !p.draining.Load() && p.health.Serving()
+}
+
+func writeProbe(w http.ResponseWriter, ok bool) {
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("NOT OK"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Источники идентичности клиента
+const (
+	SourceMTLS = "mtls"
+)
+
+// Principal описывает аутентифицированного клиента
+type Principal struct {
+	Subject      string   // CommonName сертификата
+	Organization []string // O сертификата
+	DNSNames     []string
+	URIs         []string // SAN URI, например SPIFFE ID
+	Tenant       string   // claim tenant; для mTLS - первое значение O сертификата
+	Source       string
+}
+
+type principalKey struct{}
+
+// NewContext возвращает контекст с сохраненным Principal
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает Principal, сохраненный в контексте
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// FromPeer извлекает идентичность из проверенного клиентского сертификата.
+// Возвращает false, если соединение не TLS или клиент не предъявил
+// сертификат, прошедший проверку.
+func FromPeer(ctx context.Context) (Principal, bool) {
+	pr, ok := peer.FromContext(ctx)
+	if !ok || pr.AuthInfo == nil {
+		return Principal{}, false
+	}
+
+	info, ok := pr.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return Principal{}, false
+	}
+
+	leaf := info.State.VerifiedChains[0][0]
+	uris := make([]string, 0, len(leaf.URIs))
+	for _, u := range leaf.URIs {
+		uris = append(uris, u.String())
+	}
+
+	var tenantID string
+	if len(leaf.Subject.Organization) > 0 {
+		tenantID = leaf.Subject.Organization[0]
+	}
+
+	return Principal{
+		Subject:      leaf.Subject.CommonName,
+		Organization: leaf.Subject.Organization,
+		DNSNames:     leaf.DNSNames,
+		URIs:         uris,
+		Tenant:       tenantID,
+		Source:       SourceMTLS,
+	}, true
+}
+
+// UnaryServerInterceptor сохраняет идентичность клиента в контексте запроса
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(withPeerPrincipal(ctx), req)
+	}
+}
+
+// StreamServerInterceptor сохраняет идентичность клиента в контексте стрима
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = withPeerPrincipal(ss.Context())
+		return handler(srv, wrapped)
+	}
+}
+
+func withPeerPrincipal(ctx context.Context) context.Context {
+	if p, ok := FromPeer(ctx); ok {
+		return NewContext(ctx, p)
+	}
+	return ctx
+}
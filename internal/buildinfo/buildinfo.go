@@ -0,0 +1,117 @@
+// Package buildinfo описывает версию и происхождение сборки
+package buildinfo
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// Значения задаются при сборке, например:
+//
+//	go build -ldflags "-X ms_template/internal/buildinfo.version=v1.2.3 \
+//	  -X ms_template/internal/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X ms_template/internal/buildinfo.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Незаданные значения берутся из debug.ReadBuildInfo: версия модуля
+// при go install, ревизия и время коммита из сведений VCS.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// devVersion - версия сборки без ldflags и версии модуля
+const devVersion = "dev"
+
+// Info - сведения о сборке
+type Info struct {
+	Version   string // например v1.2.3; dev для локальной сборки
+	Commit    string // ревизия VCS; пустая, если неизвестна
+	Date      string // время сборки или коммита в RFC 3339
+	Modified  bool   // собрано из рабочей копии с незакоммиченными изменениями
+	GoVersion string
+}
+
+var get = sync.OnceValue(func() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return resolve(version, commit, date, bi)
+})
+
+// Get возвращает сведения о текущей сборке
+func Get() Info {
+	return get()
+}
+
+// resolve дополняет значения из ldflags сведениями bi, который может быть nil
+func resolve(version, commit, date string, bi *debug.BuildInfo) Info {
+	info := Info{Version: version, Commit: commit, Date: date}
+	if bi == nil {
+		if info.Version == "" {
+			info.Version = devVersion
+		}
+		return info
+	}
+
+	info.GoVersion = bi.GoVersion
+	if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	if info.Version == "" {
+		info.Version = devVersion
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// ShortCommit возвращает первые 12 символов ревизии
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+// String возвращает строку вида "v1.2.3 (commit 0123456789ab, 2026-01-02T03:04:05Z, go1.25.0)"
+func (i Info) String() string {
+	s := i.Version + " (commit "
+	switch {
+	case i.Commit == "":
+		s += "unknown"
+	case i.Modified:
+		s += i.ShortCommit() + "-dirty"
+	default:
+		s += i.ShortCommit()
+	}
+	if i.Date != "" {
+		s += ", " + i.Date
+	}
+	if i.GoVersion != "" {
+		s += ", " + i.GoVersion
+	}
+	return s + ")"
+}
+
+// LogValue выводит сведения группой атрибутов
+func (i Info) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("version", i.Version),
+		slog.String("commit", i.Commit),
+		slog.String("date", i.Date),
+		slog.Bool("modified", i.Modified),
+		slog.String("go_version", i.GoVersion),
+	)
+}
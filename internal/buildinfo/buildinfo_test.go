@@ -0,0 +1,77 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BuildInfoTestSuite struct {
+	suite.Suite
+	bi *debug.BuildInfo
+}
+
+func TestBuildInfoTestSuite(t *testing.T) {
+	suite.Run(t, new(BuildInfoTestSuite))
+}
+
+func (s *BuildInfoTestSuite) SetupTest() {
+	s.bi = &debug.BuildInfo{
+		GoVersion: "go1.25.0",
+		Main:      debug.Module{Path: "ms_template", Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.time", Value: "2026-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+}
+
+func (s *BuildInfoTestSuite) TestResolve_FallbackToBuildInfo() {
+	// Act
+	info := resolve("", "", "", s.bi)
+
+	// Assert
+	assert.Equal(s.T(), Info{
+		Version:   devVersion,
+		Commit:    "0123456789abcdef0123",
+		Date:      "2026-01-02T03:04:05Z",
+		Modified:  true,
+		GoVersion: "go1.25.0",
+	}, info)
+	assert.Equal(s.T(), "dev (commit 0123456789ab-dirty, 2026-01-02T03:04:05Z, go1.25.0)", info.String())
+}
+
+func (s *BuildInfoTestSuite) TestResolve_LdflagsTakePrecedence() {
+	// Act
+	info := resolve("v1.2.3", "abc", "2026-10-19T00:00:00Z", s.bi)
+
+	// Assert
+	assert.Equal(s.T(), "v1.2.3", info.Version)
+	assert.Equal(s.T(), "abc", info.Commit)
+	assert.Equal(s.T(), "2026-10-19T00:00:00Z", info.Date)
+	assert.Equal(s.T(), "go1.25.0", info.GoVersion)
+}
+
+func (s *BuildInfoTestSuite) TestResolve_ModuleVersion() {
+	// Arrange
+	s.bi.Main.Version = "v1.4.0"
+	s.bi.Settings = nil
+
+	// Act
+	info := resolve("", "", "", s.bi)
+
+	// Assert
+	assert.Equal(s.T(), "v1.4.0", info.Version)
+	assert.Equal(s.T(), "v1.4.0 (commit unknown, go1.25.0)", info.String())
+}
+
+func (s *BuildInfoTestSuite) TestResolve_WithoutBuildInfo() {
+	// Act
+	info := resolve("", "", "", nil)
+
+	// Assert
+	assert.Equal(s.T(), Info{Version: devVersion}, info)
+}
@@ -0,0 +1,125 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReloaderTestSuite struct {
+	suite.Suite
+	dir      string
+	certFile string
+	keyFile  string
+}
+
+func TestReloaderTestSuite(t *testing.T) {
+	suite.Run(t, new(ReloaderTestSuite))
+}
+
+func (s *ReloaderTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.certFile = filepath.Join(s.dir, "tls.crt")
+	s.keyFile = filepath.Join(s.dir, "tls.key")
+}
+
+func (s *ReloaderTestSuite) TestNewReloader_MissingFiles() {
+	// Act
+	_, err := NewReloader(slog.New(slog.NewTextHandler(io.Discard, nil)), s.certFile, s.keyFile, "", time.Second)
+
+	// Assert
+	assert.Error(s.T(), err)
+}
+
+func (s *ReloaderTestSuite) TestWatch_ReloadsRotatedCertificate() {
+	// Arrange
+	s.writeCert("first")
+	r, err := NewReloader(slog.New(slog.NewTextHandler(io.Discard, nil)), s.certFile, s.keyFile, "", 10*time.Millisecond)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "first", s.servedCommonName(r))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx)
+
+	// Act
+	// Сдвигаем mtime, чтобы изменение было заметно на ФС с грубой точностью времени
+	s.writeCert("second")
+	future := time.Now().Add(time.Minute)
+	require.NoError(s.T(), os.Chtimes(s.certFile, future, future))
+	require.NoError(s.T(), os.Chtimes(s.keyFile, future, future))
+
+	// Assert
+	assert.Eventually(s.T(), func() bool {
+		return s.servedCommonName(r) == "second"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func (s *ReloaderTestSuite) TestServerConfig_RequireClientCert() {
+	// Arrange
+	s.writeCert("server")
+	caFile := filepath.Join(s.dir, "ca.crt")
+	certPEM, err := os.ReadFile(s.certFile)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), os.WriteFile(caFile, certPEM, 0o600))
+
+	r, err := NewReloader(slog.New(slog.NewTextHandler(io.Discard, nil)), s.certFile, s.keyFile, caFile, time.Second)
+	require.NoError(s.T(), err)
+
+	// Act
+	cfg, err := r.ServerConfig(true).GetConfigForClient(&tls.ClientHelloInfo{})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+	assert.NotNil(s.T(), cfg.ClientCAs)
+}
+
+func (s *ReloaderTestSuite) servedCommonName(r *Reloader) string {
+	cfg, err := r.ServerConfig(false).GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(s.T(), err)
+	leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	require.NoError(s.T(), err)
+	return leaf.Subject.CommonName
+}
+
+// writeCert записывает самоподписанный сертификат с указанным CN
+func (s *ReloaderTestSuite) writeCert(commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(s.T(), err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(s.T(), err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), os.WriteFile(s.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(s.T(), os.WriteFile(s.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
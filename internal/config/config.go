@@ -1,66 +1,628 @@
 package config
 
 import (
-	"fmt"
+	"maps"
+	"math"
+	"net"
 	"os"
+	"slices"
+	"strings"
 	"time"
-
-	"github.com/goccy/go-yaml"
 )
 
+const (
+	TLSModeInsecure = "insecure"
+	TLSModeTLS      = "tls"
+	TLSModeMTLS     = "mtls"
+
+	RateLimitKeyPrincipal = "principal"
+	RateLimitKeyTenant    = "tenant"
+	RateLimitKeyMethod    = "method"
+	RateLimitKeyPeer      = "peer"
+
+	RateLimitBackendLocal = "local"
+	RateLimitBackendRedis = "redis"
+
+	defaultTenantID = "default"
+
+	TracingExporterNone     = "none"
+	TracingExporterOTLPGRPC = "otlp-grpc"
+	TracingExporterOTLPHTTP = "otlp-http"
+	TracingExporterStdout   = "stdout"
+	TracingExporterFile     = "file"
+
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"      // один заголовок b3
+	PropagatorB3Multi      = "b3multi" // заголовки X-B3-*
+
+	LogFormatJSON   = "json"
+	LogFormatText   = "text"
+	LogFormatLogfmt = "logfmt"
+
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+	LogOutputFile   = "file"
+)
 
 type Config struct {
-    Env            string           `yaml:"env" env-default:"local"`
-	GRPC           GRPCConfig       `yaml:"grpc"`
-    Prometheus     PrometheusConfig `yaml:"prometheus"`
+	Env        string           `yaml:"env" env-default:"local" enum:"local,dev,staging,prod"` // профиль окружения, выбирает overlay конфигурации
+	Logging    LoggingConfig    `yaml:"logging"`
+	GRPC       GRPCConfig       `yaml:"grpc"`
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+	Tenancy    TenancyConfig    `yaml:"tenancy"`
+	Limits     LimitsConfig     `yaml:"limits" reload:"true"`   // лимиты пользователя по умолчанию для всех tenant
+	Features   map[string]bool  `yaml:"features" reload:"true"` // флаги по умолчанию для всех tenant
+
+	// UserLimits - переопределения лимитов для отдельных пользователей
+	UserLimits map[string]LimitsConfig `yaml:"user_limits" reload:"true"`
+	// TenantQuota - суммарная квота tenant по умолчанию (max_note_size не используется)
+	TenantQuota LimitsConfig `yaml:"tenant_quota" reload:"true"`
+
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Health    HealthConfig    `yaml:"health"`
+	Shutdown  ShutdownConfig  `yaml:"shutdown"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	AccessLog AccessLogConfig `yaml:"access_log"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Reload    ReloadConfig    `yaml:"reload"`
+	Secrets   SecretsConfig   `yaml:"secrets"`
+}
+
+// ReloadConfig - перезагрузка конфигурации без перезапуска: по SIGHUP
+// и при изменении файла. Применяются только поля с тегом reload.
+type ReloadConfig struct {
+	// Interval - период проверки файла; 0 - только по SIGHUP
+	Interval time.Duration `yaml:"interval" env-default:"5s"`
+}
+
+// AdminConfig - административные эндпоинты: gRPC сервис admin.Admin
+// и /admin/* на HTTP сервере метрик
+type AdminConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Token - bearer токен в заголовке authorization
+	Token Secret `yaml:"token" reload:"true"`
+	// AllowedSubjects - CommonName клиентских сертификатов mTLS,
+	// которым доступен gRPC сервис без токена
+	AllowedSubjects []string `yaml:"allowed_subjects"`
+	// MaxLevelTTL ограничивает время временного изменения уровня логирования; 0 - без ограничения
+	MaxLevelTTL time.Duration `yaml:"max_level_ttl"`
+	// HTTPAddr - адрес HTTP эндпоинта /admin/loglevel. Токен передается
+	// без шифрования, поэтому допускается только loopback адрес.
+	HTTPAddr string `yaml:"http_addr" env-default:"127.0.0.1:9091"`
+}
+
+// LoggingConfig - вывод логов приложения. Незаданные level и format
+// выбираются по env: для prod - info и json.
+type LoggingConfig struct {
+	Level  string `yaml:"level" reload:"true" enum:"debug,info,warn,error"`
+	Format string `yaml:"format" enum:"json,text,logfmt"`
+	Output string `yaml:"output" env-default:"stdout" enum:"stdout,stderr,file"`
+	// AddSource добавляет файл и строку вызова
+	AddSource bool              `yaml:"add_source"`
+	File      LogFileConfig     `yaml:"file"`
+	Sampling  LogSamplingConfig `yaml:"sampling"`
+}
+
+// LogFileConfig - запись в файл с ротацией по размеру и возрасту
+type LogFileConfig struct {
+	Path       string        `yaml:"path"`
+	MaxSizeMB  int           `yaml:"max_size_mb" env-default:"100"` // размер файла, после которого он ротируется
+	MaxAge     time.Duration `yaml:"max_age"`                       // срок хранения ротированных файлов, округляется до суток; 0 - бессрочно
+	MaxBackups int           `yaml:"max_backups" env-default:"5"`   // число хранимых ротированных файлов
+	Compress   bool          `yaml:"compress"`                      // gzip для ротированных файлов
+}
+
+// LogSamplingConfig ограничивает повторяющиеся записи: за каждый Tick
+// записываются первые First записей с одинаковыми уровнем и сообщением,
+// затем каждая Thereafter-я (0 - ни одной). Ошибки не ограничиваются.
+type LogSamplingConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Tick       time.Duration `yaml:"tick" env-default:"1s"`
+	First      int           `yaml:"first" env-default:"100"`
+	Thereafter int           `yaml:"thereafter"`
+}
+
+// AccessLogConfig - журнал gRPC вызовов, одна запись на вызов
+type AccessLogConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// SuccessSampleRate - доля записываемых успешных вызовов (0..1).
+	// Вызовы с ошибкой и медленные записываются всегда.
+	SuccessSampleRate *float64 `yaml:"success_sample_rate" env-default:"1"`
+	// SlowThreshold - вызовы дольше порога не проходят выборку; 0 - порог не задан
+	SlowThreshold time.Duration `yaml:"slow_threshold"`
+}
+
+// TracingConfig - параметры OpenTelemetry трассировки
+type TracingConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	ServiceName string `yaml:"service_name" env-default:"notes_service"`
+	// SampleRatio - доля новых трасс, попадающих в выборку (0..1).
+	// Для входящих запросов решение родительского span сохраняется.
+	SampleRatio *float64 `yaml:"sample_ratio" env-default:"1"`
+	// Exporter - none | otlp-grpc | otlp-http | stdout | file
+	Exporter string `yaml:"exporter" env-default:"none" enum:"none,otlp-grpc,otlp-http,stdout,file"`
+	// Endpoint - адрес коллектора для OTLP, например localhost:4317
+	Endpoint string            `yaml:"endpoint"`
+	Insecure bool              `yaml:"insecure"`                  // OTLP без TLS
+	Headers  map[string]Secret `yaml:"headers"`                   // заголовки OTLP, например для авторизации
+	Timeout  time.Duration     `yaml:"timeout" env-default:"10s"` // на отправку одного пакета span
+	File     string            `yaml:"file"`                      // путь для exporter: file
+	// Propagators - форматы передачи контекста: tracecontext, baggage, b3, b3multi
+	Propagators []string `yaml:"propagators" env-default:"tracecontext,baggage" enum:"tracecontext,baggage,b3,b3multi"`
+}
+
+// ShutdownConfig - параметры graceful shutdown
+type ShutdownConfig struct {
+	// Timeout - общее время на остановку, включая DrainDelay
+	Timeout time.Duration `yaml:"timeout" env-default:"30s"`
+	// DrainDelay - пауза между снятием готовности и остановкой серверов
+	DrainDelay time.Duration `yaml:"drain_delay"`
+}
+
+// HealthConfig - периодичность проверок зависимостей
+type HealthConfig struct {
+	Interval time.Duration `yaml:"interval" env-default:"10s"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"2s"` // на одну проверку
+}
+
+type GRPCConfig struct {
+	Port *int `yaml:"port" env-required:"true"`
+	// Timeout - время обработки unary вызова, если клиент не передал
+	// дедлайн, и предельный дедлайн для методов без собственного значения
+	Timeout *time.Duration `yaml:"timeout" env-required:"true" reload:"true"`
+	// MethodTimeouts - предельный дедлайн отдельных методов по полному имени,
+	// например /notes.Notes/AddNote. Более поздний дедлайн клиента
+	// сокращается до него. Для стримов дедлайн задается только здесь.
+	MethodTimeouts map[string]time.Duration `yaml:"method_timeouts" reload:"true"`
+	TLS            TLSConfig                `yaml:"tls"`
+	Keepalive      KeepaliveConfig          `yaml:"keepalive"`
+	// MaxConcurrentStreams - предел одновременных вызовов на одно соединение.
+	// Сверх него клиент открывает новые соединения, и балансировщик
+	// распределяет их по репликам.
+	MaxConcurrentStreams int `yaml:"max_concurrent_streams" env-default:"1000"`
+	// MaxRecvMsgSize - предельный размер входящего сообщения в байтах
+	MaxRecvMsgSize int `yaml:"max_recv_msg_size" env-default:"4194304"`
+	// MaxSendMsgSize - предельный размер исходящего сообщения в байтах
+	MaxSendMsgSize int `yaml:"max_send_msg_size" env-default:"4194304"`
+	// InitialWindowSize - окно управления потоком HTTP/2 для вызова в байтах,
+	// не меньше 65535; 0 - подбирается по пропускной способности канала
+	InitialWindowSize int `yaml:"initial_window_size"`
+	// InitialConnWindowSize - окно управления потоком HTTP/2 для соединения
+	// в байтах, не меньше 65535; 0 - подбирается автоматически
+	InitialConnWindowSize int `yaml:"initial_conn_window_size"`
+	// ConnectionTimeout - время на установку соединения, включая TLS
+	// рукопожатие; защищает от клиентов, которые не завершают его
+	ConnectionTimeout time.Duration `yaml:"connection_timeout" env-default:"10s"`
+}
+
+// KeepaliveConfig описывает проверку и ротацию соединений. Значения по
+// умолчанию рассчитаны на работу за L4 балансировщиком: ping раньше его
+// таймаута простоя (обычно 350s и больше), а ограниченный возраст
+// соединения заставляет клиентов переподключаться и распределяет
+// нагрузку на новые реплики.
+type KeepaliveConfig struct {
+	// Time - пауза без активности, после которой сервер отправляет ping
+	Time time.Duration `yaml:"time" env-default:"1m"`
+	// Timeout - ожидание ответа на ping, после него соединение закрывается
+	Timeout time.Duration `yaml:"timeout" env-default:"20s"`
+	// MaxConnectionIdle - закрытие соединения без вызовов; 0 - без ограничения
+	MaxConnectionIdle time.Duration `yaml:"max_connection_idle" env-default:"5m"`
+	// MaxConnectionAge - предельный возраст соединения; 0 - без ограничения
+	MaxConnectionAge time.Duration `yaml:"max_connection_age" env-default:"5m"`
+	// MaxConnectionAgeGrace - время на завершение вызовов после MaxConnectionAge
+	MaxConnectionAgeGrace time.Duration              `yaml:"max_connection_age_grace" env-default:"30s"`
+	Enforcement           KeepaliveEnforcementConfig `yaml:"enforcement"`
+}
+
+// KeepaliveEnforcementConfig ограничивает ping от клиентов. Клиент,
+// нарушающий политику, получает GOAWAY и теряет соединение.
+type KeepaliveEnforcementConfig struct {
+	// MinTime - минимальный интервал между ping клиента
+	MinTime time.Duration `yaml:"min_time" env-default:"10s"`
+	// PermitWithoutStream разрешает ping на соединениях без активных вызовов
+	PermitWithoutStream bool `yaml:"permit_without_stream" env-default:"true"`
+}
+
+// TLSConfig описывает транспортную безопасность gRPC листенера.
+// Файлы сертификатов перечитываются при изменении без перезапуска процесса.
+type TLSConfig struct {
+	Mode           string        `yaml:"mode" env-default:"insecure" enum:"insecure,tls,mtls"`
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ClientCAFile   string        `yaml:"client_ca_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env-default:"10s"`
+}
+
+type PrometheusConfig struct {
+	Port *int `yaml:"port" env-default:"9090"`
+	// TenantLabel добавляет метку tenant к gRPC метрикам
+	TenantLabel bool `yaml:"tenant_label"`
+	// MaxTenantLabels ограничивает число различных значений метки tenant,
+	// остальные tenant попадают в общее значение
+	MaxTenantLabels int `yaml:"max_tenant_labels" env-default:"100"`
+}
+
+// RateLimitConfig описывает ограничение частоты gRPC вызовов.
+// Корзина токенов заводится на каждый метод и комбинацию ключей из KeyBy.
+type RateLimitConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	KeyBy   []string `yaml:"key_by" env-default:"principal" enum:"principal,tenant,method,peer"`
+	// Backend - local (в памяти реплики) или redis (общий лимит для всех реплик)
+	Backend string      `yaml:"backend" env-default:"local" enum:"local,redis"`
+	Redis   RedisConfig `yaml:"redis"`
+	// Default применяется к методам без собственного правила;
+	// nil - такие методы не ограничиваются
+	Default *RateLimitRule           `yaml:"default" reload:"true"`
+	Methods map[string]RateLimitRule `yaml:"methods" reload:"true"` // полное имя метода, например /notes.Notes/AddNote
+}
+
+// RedisConfig - подключение к Redis для распределенного лимитера
+type RedisConfig struct {
+	Addr     string        `yaml:"addr"`
+	Password Secret        `yaml:"password" reload:"true"`
+	DB       int           `yaml:"db"`
+	Prefix   string        `yaml:"prefix" env-default:"ratelimit:"` // префикс ключей корзин
+	Timeout  time.Duration `yaml:"timeout" env-default:"50ms"`      // после него используется локальный лимит
+	// Backoff - время после ошибки Redis, в течение которого решения
+	// принимает только локальный лимит, не дожидаясь timeout
+	Backoff time.Duration `yaml:"backoff" env-default:"5s"`
+}
+
+// RateLimitRule - параметры token bucket
+type RateLimitRule struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+// TenancyConfig описывает определение tenant для входящих запросов
+type TenancyConfig struct {
+	Header   string `yaml:"header"`   // ключ metadata, по умолчанию x-tenant-id
+	Default  string `yaml:"default"`  // tenant для запросов без явного tenant
+	Required bool   `yaml:"required"` // отклонять запросы без tenant
+	// TrustedProxies - Subject (CN сертификата) клиентов, которые передают
+	// tenant в metadata, например шлюзов. От остальных клиентов metadata
+	// с tenant отклоняется.
+	TrustedProxies []string                `yaml:"trusted_proxies"`
+	Overrides      map[string]TenantConfig `yaml:"overrides" reload:"true"`
+}
+
+// TenantConfig - переопределения настроек для конкретного tenant
+type TenantConfig struct {
+	Limits     LimitsConfig            `yaml:"limits"`
+	Features   map[string]bool         `yaml:"features"`
+	UserLimits map[string]LimitsConfig `yaml:"user_limits"`
+	Quota      LimitsConfig            `yaml:"quota"`
 }
 
-type GRPCConfig struct {  
-    Port    *int           `yaml:"port"`  
-    Timeout *time.Duration `yaml:"timeout"`  
+// LimitsConfig - квоты хранения, применяемые в бизнес-логике.
+// nil означает отсутствие ограничения.
+type LimitsConfig struct {
+	MaxNoteSize *int   `yaml:"max_note_size"` // байт в заголовке и тексте заметки
+	MaxNotes    *int   `yaml:"max_notes"`     // число заметок
+	MaxBytes    *int64 `yaml:"max_bytes"`     // суммарный размер заметок в байтах
 }
 
-type PrometheusConfig struct {  
-    Port    *int           `yaml:"port"`  
+// Merge возвращает лимиты, в которых заданные в override поля
+// перекрывают текущие
+func (l LimitsConfig) Merge(override LimitsConfig) LimitsConfig {
+	if override.MaxNoteSize != nil {
+		l.MaxNoteSize = override.MaxNoteSize
+	}
+	if override.MaxNotes != nil {
+		l.MaxNotes = override.MaxNotes
+	}
+	if override.MaxBytes != nil {
+		l.MaxBytes = override.MaxBytes
+	}
+	return l
 }
 
+// isValid проверяет всю конфигурацию и возвращает *ValidationError
+// со всеми найденными ошибками
 func (cfg Config) isValid() error {
-    if cfg.Env == "" {
-        return fmt.Errorf("переменная env не задана в конфигурации")
-    }
+	v := newValidator()
+	cfg.validate(v)
+	return v.err()
+}
+
+func (cfg Config) validate(v *validator) {
+	v.oneOf("env", cfg.Env, EnvLocal, EnvDev, EnvStaging, EnvProd)
+
+	cfg.Logging.validate(v.at("logging"))
+	cfg.GRPC.validate(v.at("grpc"))
+	cfg.Prometheus.validate(v.at("prometheus"))
+	if cfg.GRPC.Port != nil && cfg.Prometheus.Port != nil && *cfg.GRPC.Port == *cfg.Prometheus.Port {
+		v.add("prometheus.port", "порт %d уже занят grpc.port", *cfg.Prometheus.Port)
+	}
 
-    err := cfg.GRPC.isValid()
-    if err != nil {
-        return err
-    }
+	health := v.at("health")
+	health.positive("interval", cfg.Health.Interval)
+	health.positive("timeout", cfg.Health.Timeout)
+	if cfg.Health.Interval > 0 && cfg.Health.Timeout > cfg.Health.Interval {
+		health.add("timeout", "не может превышать health.interval (%s)", cfg.Health.Interval)
+	}
 
-    return nil
+	shutdown := v.at("shutdown")
+	shutdown.positive("timeout", cfg.Shutdown.Timeout)
+	shutdown.nonNegative("drain_delay", cfg.Shutdown.DrainDelay)
+	if cfg.Shutdown.Timeout > 0 && cfg.Shutdown.DrainDelay >= cfg.Shutdown.Timeout {
+		shutdown.add("drain_delay", "должен быть меньше shutdown.timeout (%s)", cfg.Shutdown.Timeout)
+	}
+
+	cfg.Limits.validate(v.at("limits"))
+	cfg.TenantQuota.validate(v.at("tenant_quota"))
+	for _, id := range slices.Sorted(maps.Keys(cfg.UserLimits)) {
+		l := cfg.UserLimits[id]
+		l.validate(v.at("user_limits").key(id))
+	}
+	if slices.Contains(cfg.Tenancy.TrustedProxies, "") {
+		v.at("tenancy").add("trusted_proxies", "содержит пустой Subject")
+	}
+	for _, id := range slices.Sorted(maps.Keys(cfg.Tenancy.Overrides)) {
+		t := cfg.Tenancy.Overrides[id]
+		tenant := v.at("tenancy.overrides").key(id)
+		t.Limits.validate(tenant.at("limits"))
+		t.Quota.validate(tenant.at("quota"))
+		for _, userID := range slices.Sorted(maps.Keys(t.UserLimits)) {
+			l := t.UserLimits[userID]
+			l.validate(tenant.at("user_limits").key(userID))
+		}
+	}
+
+	cfg.RateLimit.validate(v.at("rate_limit"))
+	cfg.Tracing.validate(v.at("tracing"))
+	cfg.AccessLog.validate(v.at("access_log"))
+	cfg.Admin.validate(v.at("admin"))
+	v.at("reload").nonNegative("interval", cfg.Reload.Interval)
+
+	secrets := v.at("secrets")
+	secrets.nonNegative("refresh_interval", cfg.Secrets.RefreshInterval)
+	secrets.at("vault").positive("timeout", cfg.Secrets.Vault.Timeout)
 }
 
-func (g GRPCConfig) isValid() error {
-    if g.Port == nil {
-        return fmt.Errorf("порт gRPC не задан")
-    }
-    if g.Timeout == nil {
-        return fmt.Errorf("таймаут для gRPC не задан")
-    }
-    return nil
+func (g GRPCConfig) validate(v *validator) {
+	v.port("port", g.Port)
+	if g.Timeout == nil {
+		v.add("timeout", "не задан")
+	} else {
+		v.positive("timeout", *g.Timeout)
+	}
+	for _, method := range slices.Sorted(maps.Keys(g.MethodTimeouts)) {
+		if !isFullMethod(method) {
+			v.at("method_timeouts").key(method).add("", "ожидается полное имя метода вида /package.Service/Method")
+		}
+		v.at("method_timeouts").key(method).positive("", g.MethodTimeouts[method])
+	}
+	g.TLS.validate(v.at("tls"))
+	g.Keepalive.validate(v.at("keepalive"))
+
+	if g.MaxConcurrentStreams < 1 || g.MaxConcurrentStreams > math.MaxInt32 {
+		v.add("max_concurrent_streams", "должен быть в диапазоне 1-%d, задано %d", math.MaxInt32, g.MaxConcurrentStreams)
+	}
+	if g.MaxRecvMsgSize < 1 {
+		v.add("max_recv_msg_size", "должен быть положительным, задано %d", g.MaxRecvMsgSize)
+	}
+	if g.MaxSendMsgSize < 1 {
+		v.add("max_send_msg_size", "должен быть положительным, задано %d", g.MaxSendMsgSize)
+	}
+	v.windowSize("initial_window_size", g.InitialWindowSize)
+	v.windowSize("initial_conn_window_size", g.InitialConnWindowSize)
+	v.positive("connection_timeout", g.ConnectionTimeout)
 }
 
+func (k KeepaliveConfig) validate(v *validator) {
+	// gRPC поднимает меньшие значения до 1s, поэтому они отклоняются явно
+	if k.Time < time.Second {
+		v.add("time", "должен быть не меньше 1s, задано %s", k.Time)
+	}
+	v.positive("timeout", k.Timeout)
+	v.nonNegative("max_connection_idle", k.MaxConnectionIdle)
+	v.nonNegative("max_connection_age", k.MaxConnectionAge)
+	v.nonNegative("max_connection_age_grace", k.MaxConnectionAgeGrace)
+	v.at("enforcement").nonNegative("min_time", k.Enforcement.MinTime)
+}
 
-func LoadConfig(path string) (*Config, error) {
-    var cfg Config
-    data, err := os.ReadFile(path)
-    if err != nil {
-        return nil, err
-    }
+func (p PrometheusConfig) validate(v *validator) {
+	v.port("port", p.Port)
+	if p.MaxTenantLabels < 0 {
+		v.add("max_tenant_labels", "не может быть отрицательным")
+	}
+}
+
+func (t TLSConfig) validate(v *validator) {
+	v.oneOf("mode", t.Mode, TLSModeInsecure, TLSModeTLS, TLSModeMTLS)
+	if t.Mode != TLSModeTLS && t.Mode != TLSModeMTLS {
+		return
+	}
+
+	if t.CertFile == "" {
+		v.add("cert_file", "обязателен для режима %q", t.Mode)
+	}
+	if t.KeyFile == "" {
+		v.add("key_file", "обязателен для режима %q", t.Mode)
+	}
+	if t.Mode == TLSModeMTLS && t.ClientCAFile == "" {
+		v.add("client_ca_file", "обязателен для режима %q", t.Mode)
+	}
+	v.positive("reload_interval", t.ReloadInterval)
+}
+
+func (l LimitsConfig) validate(v *validator) {
+	if l.MaxNoteSize != nil && *l.MaxNoteSize <= 0 {
+		v.add("max_note_size", "должен быть положительным")
+	}
+	// 0 в GetUsage означает отсутствие ограничения, поэтому в конфигурации
+	// нулевой лимит не допускается: без ограничения параметр не задается
+	if l.MaxNotes != nil && *l.MaxNotes <= 0 {
+		v.add("max_notes", "должен быть положительным; чтобы снять ограничение, не задавайте параметр")
+	}
+	if l.MaxBytes != nil && *l.MaxBytes <= 0 {
+		v.add("max_bytes", "должен быть положительным; чтобы снять ограничение, не задавайте параметр")
+	}
+}
+
+func (r RateLimitConfig) validate(v *validator) {
+	if !r.Enabled {
+		return
+	}
+
+	v.oneOf("backend", r.Backend, RateLimitBackendLocal, RateLimitBackendRedis)
+	if r.Backend == RateLimitBackendRedis {
+		redis := v.at("redis")
+		if _, _, err := net.SplitHostPort(r.Redis.Addr); err != nil {
+			redis.add("addr", "ожидается адрес host:port, задано %q", r.Redis.Addr)
+		}
+		if r.Redis.DB < 0 {
+			redis.add("db", "не может быть отрицательным")
+		}
+		redis.nonNegative("backoff", r.Redis.Backoff)
+		redis.positive("timeout", r.Redis.Timeout)
+	}
+
+	for _, key := range r.KeyBy {
+		v.oneOf("key_by", key, RateLimitKeyPrincipal, RateLimitKeyTenant, RateLimitKeyMethod, RateLimitKeyPeer)
+	}
+
+	if r.Default != nil {
+		r.Default.validate(v.at("default"))
+	}
+	for _, method := range slices.Sorted(maps.Keys(r.Methods)) {
+		rule := r.Methods[method]
+		if !isFullMethod(method) {
+			v.at("methods").key(method).add("", "ожидается полное имя метода вида /package.Service/Method")
+		}
+		rule.validate(v.at("methods").key(method))
+	}
+}
 
-    err = yaml.Unmarshal(data, &cfg)
-    if err != nil {
-        return nil, err
-    }
+// isFullMethod проверяет формат полного имени gRPC метода /package.Service/Method
+func isFullMethod(method string) bool {
+	return strings.HasPrefix(method, "/") && strings.Count(method, "/") == 2
+}
+
+func (r RateLimitRule) validate(v *validator) {
+	if r.RPS <= 0 {
+		v.add("rps", "должен быть положительным")
+	}
+	if r.Burst < 1 {
+		v.add("burst", "должен быть не меньше 1")
+	}
+}
+
+func (t TracingConfig) validate(v *validator) {
+	if !t.Enabled {
+		return
+	}
+
+	if t.SampleRatio != nil && (*t.SampleRatio < 0 || *t.SampleRatio > 1) {
+		v.add("sample_ratio", "должен быть в диапазоне от 0 до 1")
+	}
+	v.positive("timeout", t.Timeout)
+
+	v.oneOf("exporter", t.Exporter, TracingExporterNone, TracingExporterOTLPGRPC, TracingExporterOTLPHTTP, TracingExporterStdout, TracingExporterFile)
+	switch t.Exporter {
+	case TracingExporterOTLPGRPC, TracingExporterOTLPHTTP:
+		if t.Endpoint == "" {
+			v.add("endpoint", "обязателен для exporter %q", t.Exporter)
+		}
+	case TracingExporterFile:
+		if t.File == "" {
+			v.add("file", "обязателен для exporter %q", t.Exporter)
+		}
+	}
 
-    err = cfg.isValid()
+	for _, p := range t.Propagators {
+		v.oneOf("propagators", p, PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorB3Multi)
+	}
+}
+
+func (l LoggingConfig) validate(v *validator) {
+	v.oneOf("level", l.Level, "debug", "info", "warn", "error")
+	v.oneOf("format", l.Format, LogFormatJSON, LogFormatText, LogFormatLogfmt)
+	v.oneOf("output", l.Output, LogOutputStdout, LogOutputStderr, LogOutputFile)
+
+	if l.Output == LogOutputFile {
+		file := v.at("file")
+		if l.File.Path == "" {
+			file.add("path", "обязателен для output %q", l.Output)
+		}
+		if l.File.MaxSizeMB <= 0 {
+			file.add("max_size_mb", "должен быть положительным")
+		}
+		file.nonNegative("max_age", l.File.MaxAge)
+		if l.File.MaxBackups < 0 {
+			file.add("max_backups", "не может быть отрицательным")
+		}
+	}
 
-    return &cfg, err
-} 
\ No newline at end of file
+	if l.Sampling.Enabled {
+		sampling := v.at("sampling")
+		sampling.positive("tick", l.Sampling.Tick)
+		if l.Sampling.First < 1 {
+			sampling.add("first", "должен быть не меньше 1")
+		}
+		if l.Sampling.Thereafter < 0 {
+			sampling.add("thereafter", "не может быть отрицательным")
+		}
+	}
+}
+
+func (a AccessLogConfig) validate(v *validator) {
+	if a.SuccessSampleRate != nil && (*a.SuccessSampleRate < 0 || *a.SuccessSampleRate > 1) {
+		v.add("success_sample_rate", "должен быть в диапазоне от 0 до 1")
+	}
+	v.nonNegative("slow_threshold", a.SlowThreshold)
+}
+
+func (a AdminConfig) validate(v *validator) {
+	if !a.Enabled {
+		return
+	}
+	if a.Token == "" && len(a.AllowedSubjects) == 0 {
+		v.add("", "необходимо задать token или allowed_subjects")
+	}
+	v.nonNegative("max_level_ttl", a.MaxLevelTTL)
+
+	host, _, err := net.SplitHostPort(a.HTTPAddr)
+	if err != nil {
+		v.add("http_addr", "ожидается адрес host:port, задано %q", a.HTTPAddr)
+	} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		v.add("http_addr", "допускается только loopback адрес, задано %q", a.HTTPAddr)
+	}
+}
+
+// setDefaults заполняет значения по умолчанию, которые нельзя задать
+// тегом env-default, например зависящие от других параметров
+func (cfg *Config) setDefaults() {
+	cfg.Logging.setDefaults(cfg.Env)
+
+	if cfg.Tenancy.Default == "" && !cfg.Tenancy.Required {
+		cfg.Tenancy.Default = defaultTenantID
+	}
+}
+
+func (l *LoggingConfig) setDefaults(env string) {
+	if l.Level == "" {
+		l.Level = "debug"
+		if env == EnvProd {
+			l.Level = "info"
+		}
+	}
+	if l.Format == "" {
+		l.Format = LogFormatJSON
+		if env == EnvLocal {
+			l.Format = LogFormatText
+		}
+	}
+}
+
+// LoadConfig загружает конфигурацию из файла с переопределением
+// переменными окружения процесса
+func LoadConfig(path string) (*Config, error) {
+	loaded, err := Load(LoadOptions{Path: path, Environ: os.Environ()})
+	if err != nil {
+		return nil, err
+	}
+	return loaded.Config, nil
+}
@@ -0,0 +1,157 @@
+// docgen собирает комментарии полей структур пакета config в файл
+// docs_gen.go, из которого JSON Schema берет описания параметров.
+// Запускается через go generate в каталоге internal/config.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	output = "docs_gen.go"
+	// root - структура конфигурации, описания собираются только для
+	// типов, достижимых из нее
+	root = "Config"
+)
+
+func main() {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		log.Fatalf("Не удалось найти файлы пакета: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	structs := map[string]*ast.StructType{}
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatalf("Не удалось разобрать %s: %v", name, err)
+		}
+		structsOf(file, structs)
+	}
+	docs := map[string]string{}
+	collect(structs, root, docs, map[string]bool{})
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by docgen; DO NOT EDIT.\n\npackage config\n\n")
+	b.WriteString("// fieldDocs - комментарии полей по ключу Тип.Поле\n")
+	b.WriteString("var fieldDocs = map[string]string{\n")
+	for _, key := range slices.Sorted(maps.Keys(docs)) {
+		fmt.Fprintf(&b, "%q: %q,\n", key, docs[key])
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("Не удалось отформатировать %s: %v", output, err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatalf("Не удалось записать %s: %v", output, err)
+	}
+}
+
+// structsOf добавляет в structs структуры, объявленные в файле
+func structsOf(file *ast.File, structs map[string]*ast.StructType) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if st, ok := typeSpec.Type.(*ast.StructType); ok {
+				structs[typeSpec.Name.Name] = st
+			}
+		}
+	}
+}
+
+// collect добавляет в docs комментарии параметров структуры name и
+// структур, на которые ссылаются ее поля. Поля без тега yaml не
+// являются параметрами и пропускаются.
+func collect(structs map[string]*ast.StructType, name string, docs map[string]string, seen map[string]bool) {
+	st, ok := structs[name]
+	if !ok || seen[name] {
+		return
+	}
+	seen[name] = true
+
+	for _, field := range st.Fields.List {
+		if !isParameter(field) {
+			continue
+		}
+		for _, ref := range referenced(field.Type) {
+			collect(structs, ref, docs, seen)
+		}
+
+		group := field.Doc
+		if group == nil {
+			group = field.Comment
+		}
+		if group == nil {
+			continue
+		}
+		for _, fieldName := range field.Names {
+			docs[name+"."+fieldName.Name] = clean(group.Text(), fieldName.Name)
+		}
+	}
+}
+
+// isParameter сообщает, задан ли у поля тег yaml
+func isParameter(field *ast.Field) bool {
+	if field.Tag == nil || len(field.Names) == 0 {
+		return false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return false
+	}
+	yamlName, _, _ := strings.Cut(reflect.StructTag(tag).Get("yaml"), ",")
+	return yamlName != "" && yamlName != "-"
+}
+
+// referenced возвращает имена типов пакета в выражении типа поля,
+// включая элементы указателей, срезов и словарей
+func referenced(expr ast.Expr) []string {
+	var names []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// Типы других пакетов, например time.Duration
+			return false
+		case *ast.Ident:
+			names = append(names, n.Name)
+		}
+		return true
+	})
+	return names
+}
+
+// clean склеивает строки комментария и убирает повтор имени поля
+// в начале: "Interval - период проверки" -> "период проверки"
+func clean(text, name string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if rest, ok := strings.CutPrefix(text, name+" - "); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(text, name+" "); ok {
+		return rest
+	}
+	return text
+}
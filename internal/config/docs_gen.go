@@ -0,0 +1,70 @@
+// Code generated by docgen; DO NOT EDIT.
+
+package config
+
+// fieldDocs - комментарии полей по ключу Тип.Поле
+var fieldDocs = map[string]string{
+	"AccessLogConfig.SlowThreshold":                  "вызовы дольше порога не проходят выборку; 0 - порог не задан",
+	"AccessLogConfig.SuccessSampleRate":              "доля записываемых успешных вызовов (0..1). Вызовы с ошибкой и медленные записываются всегда.",
+	"AdminConfig.AllowedSubjects":                    "CommonName клиентских сертификатов mTLS, которым доступен gRPC сервис без токена",
+	"AdminConfig.HTTPAddr":                           "адрес HTTP эндпоинта /admin/loglevel. Токен передается без шифрования, поэтому допускается только loopback адрес.",
+	"AdminConfig.MaxLevelTTL":                        "ограничивает время временного изменения уровня логирования; 0 - без ограничения",
+	"AdminConfig.Token":                              "bearer токен в заголовке authorization",
+	"Config.Env":                                     "профиль окружения, выбирает overlay конфигурации",
+	"Config.Features":                                "флаги по умолчанию для всех tenant",
+	"Config.Limits":                                  "лимиты пользователя по умолчанию для всех tenant",
+	"Config.TenantQuota":                             "суммарная квота tenant по умолчанию (max_note_size не используется)",
+	"Config.UserLimits":                              "переопределения лимитов для отдельных пользователей",
+	"GRPCConfig.ConnectionTimeout":                   "время на установку соединения, включая TLS рукопожатие; защищает от клиентов, которые не завершают его",
+	"GRPCConfig.InitialConnWindowSize":               "окно управления потоком HTTP/2 для соединения в байтах, не меньше 65535; 0 - подбирается автоматически",
+	"GRPCConfig.InitialWindowSize":                   "окно управления потоком HTTP/2 для вызова в байтах, не меньше 65535; 0 - подбирается по пропускной способности канала",
+	"GRPCConfig.MaxConcurrentStreams":                "предел одновременных вызовов на одно соединение. Сверх него клиент открывает новые соединения, и балансировщик распределяет их по репликам.",
+	"GRPCConfig.MaxRecvMsgSize":                      "предельный размер входящего сообщения в байтах",
+	"GRPCConfig.MaxSendMsgSize":                      "предельный размер исходящего сообщения в байтах",
+	"GRPCConfig.MethodTimeouts":                      "предельный дедлайн отдельных методов по полному имени, например /notes.Notes/AddNote. Более поздний дедлайн клиента сокращается до него. Для стримов дедлайн задается только здесь.",
+	"GRPCConfig.Timeout":                             "время обработки unary вызова, если клиент не передал дедлайн, и предельный дедлайн для методов без собственного значения",
+	"HealthConfig.Timeout":                           "на одну проверку",
+	"KeepaliveConfig.MaxConnectionAge":               "предельный возраст соединения; 0 - без ограничения",
+	"KeepaliveConfig.MaxConnectionAgeGrace":          "время на завершение вызовов после MaxConnectionAge",
+	"KeepaliveConfig.MaxConnectionIdle":              "закрытие соединения без вызовов; 0 - без ограничения",
+	"KeepaliveConfig.Time":                           "пауза без активности, после которой сервер отправляет ping",
+	"KeepaliveConfig.Timeout":                        "ожидание ответа на ping, после него соединение закрывается",
+	"KeepaliveEnforcementConfig.MinTime":             "минимальный интервал между ping клиента",
+	"KeepaliveEnforcementConfig.PermitWithoutStream": "разрешает ping на соединениях без активных вызовов",
+	"LimitsConfig.MaxBytes":                          "суммарный размер заметок в байтах",
+	"LimitsConfig.MaxNoteSize":                       "байт в заголовке и тексте заметки",
+	"LimitsConfig.MaxNotes":                          "число заметок",
+	"LogFileConfig.Compress":                         "gzip для ротированных файлов",
+	"LogFileConfig.MaxAge":                           "срок хранения ротированных файлов, округляется до суток; 0 - бессрочно",
+	"LogFileConfig.MaxBackups":                       "число хранимых ротированных файлов",
+	"LogFileConfig.MaxSizeMB":                        "размер файла, после которого он ротируется",
+	"LoggingConfig.AddSource":                        "добавляет файл и строку вызова",
+	"PrometheusConfig.MaxTenantLabels":               "ограничивает число различных значений метки tenant, остальные tenant попадают в общее значение",
+	"PrometheusConfig.TenantLabel":                   "добавляет метку tenant к gRPC метрикам",
+	"RateLimitConfig.Backend":                        "local (в памяти реплики) или redis (общий лимит для всех реплик)",
+	"RateLimitConfig.Default":                        "применяется к методам без собственного правила; nil - такие методы не ограничиваются",
+	"RateLimitConfig.Methods":                        "полное имя метода, например /notes.Notes/AddNote",
+	"RedisConfig.Backoff":                            "время после ошибки Redis, в течение которого решения принимает только локальный лимит, не дожидаясь timeout",
+	"RedisConfig.Prefix":                             "префикс ключей корзин",
+	"RedisConfig.Timeout":                            "после него используется локальный лимит",
+	"ReloadConfig.Interval":                          "период проверки файла; 0 - только по SIGHUP",
+	"SecretsConfig.RefreshInterval":                  "период повторного чтения секретов; 0 - только при загрузке",
+	"ShutdownConfig.DrainDelay":                      "пауза между снятием готовности и остановкой серверов",
+	"ShutdownConfig.Timeout":                         "общее время на остановку, включая DrainDelay",
+	"TenancyConfig.Default":                          "tenant для запросов без явного tenant",
+	"TenancyConfig.Header":                           "ключ metadata, по умолчанию x-tenant-id",
+	"TenancyConfig.Required":                         "отклонять запросы без tenant",
+	"TenancyConfig.TrustedProxies":                   "Subject (CN сертификата) клиентов, которые передают tenant в metadata, например шлюзов. От остальных клиентов metadata с tenant отклоняется.",
+	"TracingConfig.Endpoint":                         "адрес коллектора для OTLP, например localhost:4317",
+	"TracingConfig.Exporter":                         "none | otlp-grpc | otlp-http | stdout | file",
+	"TracingConfig.File":                             "путь для exporter: file",
+	"TracingConfig.Headers":                          "заголовки OTLP, например для авторизации",
+	"TracingConfig.Insecure":                         "OTLP без TLS",
+	"TracingConfig.Propagators":                      "форматы передачи контекста: tracecontext, baggage, b3, b3multi",
+	"TracingConfig.SampleRatio":                      "доля новых трасс, попадающих в выборку (0..1). Для входящих запросов решение родительского span сохраняется.",
+	"TracingConfig.Timeout":                          "на отправку одного пакета span",
+	"VaultConfig.Addr":                               "например http://127.0.0.1:8200",
+	"VaultConfig.DevFile":                            "YAML файл, заменяющий Vault при локальной разработке: ключи верхнего уровня - пути mount/path, значения - словари секретов",
+	"VaultConfig.Namespace":                          "Vault Enterprise",
+	"VaultConfig.Token":                              "обычно env://VAULT_TOKEN",
+}
@@ -0,0 +1,436 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// EnvPrefix - префикс переменных окружения: grpc.port задается через NOTES_GRPC_PORT
+const EnvPrefix = "NOTES_"
+
+// Source - слой, из которого получено значение параметра
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// LoadOptions - слои конфигурации. Значения применяются по возрастанию
+// приоритета: тег env-default, YAML файлы (включения, базовый файл,
+// overlay профиля), переменные окружения, флаги.
+type LoadOptions struct {
+	Path    string   // базовый YAML файл; пустой путь - без файлов
+	Environ []string // в формате os.Environ
+	Flags   *Flags   // nil - без флагов
+}
+
+// Loaded - итоговая конфигурация и источник каждого параметра
+type Loaded struct {
+	Config  *Config
+	Sources map[string]Source // путь параметра через точку, например grpc.port
+	// Refs - ссылки на секреты, из которых получены значения, по путям параметров
+	Refs map[string]string
+	// Files - прочитанные YAML файлы в порядке применения: включения,
+	// базовый файл, overlay профиля
+	Files []string
+
+	layers []layer
+}
+
+// environMap разбирает переменные в формате os.Environ
+func environMap(environ []string) map[string]string {
+	m := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			m[name] = value
+		}
+	}
+	return m
+}
+
+// Load собирает конфигурацию из слоев, заполняет вычисляемые значения
+// по умолчанию и проверяет результат
+func Load(opts LoadOptions) (*Loaded, error) {
+	cfg := &Config{}
+	params := parameters(reflect.ValueOf(cfg).Elem(), "")
+	sources := make(map[string]Source, len(params))
+
+	for _, p := range params {
+		sources[p.path] = SourceDefault
+		if p.def == "" {
+			continue
+		}
+		if err := p.set(p.def); err != nil {
+			return nil, fmt.Errorf("env-default для %s: %w", p.path, err)
+		}
+	}
+
+	environ := environMap(opts.Environ)
+
+	// Ошибки значений и неизвестные ключи файлов собираются вместе с ошибками проверки
+	v := newValidator()
+	// invalid - пути параметров, значения которых не удалось разобрать.
+	// Параметр сохраняет предыдущее значение, и ошибки проверки для него
+	// не выводятся, чтобы не дублировать ошибку разбора.
+	invalid := map[string]bool{}
+	var layers []layer
+	profile, err := selectProfile(opts, environ, nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Path != "" {
+		if layers, profile, err = readLayers(opts, environ); err != nil {
+			return nil, err
+		}
+		for _, l := range layers {
+			// Типы проверяются по каждому файлу, чтобы ошибка указывала на него
+			for _, path := range checkNode(v, l.doc, reflect.TypeFor[Config](), l.path) {
+				invalid[path] = true
+				deletePath(l.data, splitPath(path))
+			}
+			for _, p := range params {
+				if findNode(l.doc, splitPath(p.path)) != nil {
+					sources[p.path] = SourceFile
+				}
+			}
+		}
+
+		data, err := yaml.Marshal(mergeLayers(layers))
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range params {
+		value, ok := environ[p.env]
+		if !ok || !p.scalar() {
+			continue
+		}
+		if err := p.set(value); err != nil {
+			*v.errs = append(*v.errs, FieldError{Path: p.path, Location: "переменная " + p.env, Message: err.Error()})
+			invalid[p.path] = true
+			continue
+		}
+		sources[p.path] = SourceEnv
+	}
+
+	if opts.Flags != nil {
+		for _, p := range params {
+			value, ok := opts.Flags.values[p.path]
+			if !ok {
+				continue
+			}
+			if err := p.set(value); err != nil {
+				*v.errs = append(*v.errs, FieldError{Path: p.path, Location: "флаг --" + p.path, Message: err.Error()})
+				invalid[p.path] = true
+				continue
+			}
+			sources[p.path] = SourceFlag
+		}
+	}
+
+	refs := resolveSecrets(cfg, environ, v)
+
+	// Вычисляемые значения (например, зависящие от env) отмечаются как default
+	before := make([]string, len(params))
+	for i, p := range params {
+		before[i] = p.String()
+	}
+	cfg.setDefaults()
+	for i, p := range params {
+		if p.String() != before[i] {
+			sources[p.path] = SourceDefault
+		}
+	}
+
+	loaded := &Loaded{Config: cfg, Sources: sources, Refs: refs, layers: layers}
+	for _, l := range layers {
+		loaded.Files = append(loaded.Files, l.path)
+	}
+	parsed := len(*v.errs)
+	cfg.validate(v)
+	if cfg.Env != profile {
+		v.add("env", "значение %q не совпадает с выбранным профилем %q", cfg.Env, profile)
+	}
+	errs := (*v.errs)[:parsed]
+	for _, e := range (*v.errs)[parsed:] {
+		if !coveredBy(e.Path, invalid) {
+			errs = append(errs, e)
+		}
+	}
+	*v.errs = errs
+	if err := v.err(); err != nil {
+		verr := err.(*ValidationError)
+		for i := range verr.Errors {
+			if verr.Errors[i].Location == "" {
+				verr.Errors[i].Location = loaded.location(verr.Errors[i].Path)
+			}
+		}
+		return loaded, verr
+	}
+	return loaded, nil
+}
+
+// coveredBy сообщает, относится ли путь к параметру из invalid или
+// к его вложенным параметрам
+func coveredBy(path string, invalid map[string]bool) bool {
+	for p := range invalid {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// location описывает, где задано значение параметра: для файла -
+// файл, строка и столбец, для переменной и флага - их имя
+func (l *Loaded) location(path string) string {
+	keys := splitPath(path)
+
+	// Для элементов словарей источник берется у самого словаря
+	param := path
+	for i := len(keys); i > 0; i-- {
+		prefix := strings.Join(keys[:i], ".")
+		if _, ok := l.Sources[prefix]; ok {
+			param = prefix
+			break
+		}
+	}
+
+	switch l.Sources[param] {
+	case SourceEnv:
+		return "переменная " + envName(param)
+	case SourceFlag:
+		return "флаг --" + param
+	case SourceFile:
+		// Значение задано последним файлом, в котором встречается путь
+		for i := len(l.layers) - 1; i >= 0; i-- {
+			if node := findNode(l.layers[i].doc, keys); node != nil {
+				pos := node.GetToken().Position
+				return fmt.Sprintf("%s:%d:%d", l.layers[i].path, pos.Line, pos.Column)
+			}
+		}
+		for i := len(l.layers) - 1; i >= 0; i-- {
+			if findNode(l.layers[i].doc, keys[:1]) != nil {
+				return l.layers[i].path
+			}
+		}
+	}
+	return "значение по умолчанию"
+}
+
+// Dump выводит итоговые значения всех параметров с их источниками.
+// Значения типа Secret скрываются, для секретов из ссылок выводится ссылка.
+func (l *Loaded) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ПАРАМЕТР\tЗНАЧЕНИЕ\tИСТОЧНИК")
+	for _, p := range parameters(reflect.ValueOf(l.Config).Elem(), "") {
+		value := p.String()
+		if ref, ok := l.Refs[p.path]; ok {
+			value += " (" + ref + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.path, value, l.Sources[p.path])
+	}
+	return tw.Flush()
+}
+
+// Flags - значения флагов командной строки вида --grpc.port=9090
+type Flags struct {
+	values map[string]string
+}
+
+// RegisterFlags добавляет в fs флаг для каждого скалярного параметра
+// конфигурации. Значения читаются после fs.Parse через LoadOptions.Flags.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{values: map[string]string{}}
+	for _, p := range parameters(reflect.ValueOf(&Config{}).Elem(), "") {
+		if !p.scalar() {
+			continue
+		}
+		fs.Var(flagValue{flags: f, path: p.path, bool: p.kind() == reflect.Bool}, p.path,
+			fmt.Sprintf("переопределяет %s (переменная %s)", p.path, p.env))
+	}
+	return f
+}
+
+type flagValue struct {
+	flags *Flags
+	path  string
+	bool  bool
+}
+
+func (v flagValue) String() string {
+	if v.flags == nil {
+		return ""
+	}
+	return v.flags.values[v.path]
+}
+
+func (v flagValue) Set(value string) error {
+	v.flags.values[v.path] = value
+	return nil
+}
+
+func (v flagValue) IsBoolFlag() bool { return v.bool }
+
+// parameter - конечное поле конфигурации
+type parameter struct {
+	path   string
+	env    string
+	def    string
+	reload bool // применяется без перезапуска, см. Watcher
+	value  reflect.Value
+}
+
+// parameters обходит структуру и возвращает ее конечные поля. Путь
+// строится из тегов yaml, имя переменной - из пути или тега env.
+// Map и указатели на структуры не раскрываются и задаются только в YAML.
+// Тег reload вложенной структуры действует на все ее поля.
+func parameters(v reflect.Value, prefix string) []parameter {
+	return walk(v, prefix, false)
+}
+
+func walk(v reflect.Value, prefix string, reload bool) []parameter {
+	var params []parameter
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := prefix + name
+		fieldReload := reload || field.Tag.Get("reload") == "true"
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[time.Duration]() {
+			params = append(params, walk(v.Field(i), path+".", fieldReload)...)
+			continue
+		}
+
+		env := field.Tag.Get("env")
+		if env == "" {
+			env = envName(path)
+		}
+		params = append(params, parameter{
+			path:   path,
+			env:    env,
+			def:    field.Tag.Get("env-default"),
+			reload: fieldReload,
+			value:  v.Field(i),
+		})
+	}
+	return params
+}
+
+// envName возвращает имя переменной окружения для пути параметра
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// kind возвращает тип значения с учетом указателя
+func (p parameter) kind() reflect.Kind {
+	t := p.value.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind()
+}
+
+// scalar сообщает, можно ли задать параметр строкой
+func (p parameter) scalar() bool {
+	switch p.kind() {
+	case reflect.Struct, reflect.Map:
+		return false
+	case reflect.Slice:
+		return p.value.Type().Elem().Kind() == reflect.String
+	}
+	return true
+}
+
+// set разбирает строковое значение. Списки задаются через запятую.
+func (p parameter) set(raw string) error {
+	v := p.value
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == reflect.TypeFor[time.Duration]():
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.CanInt():
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.CanFloat():
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for item := range strings.SplitSeq(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("тип %s не задается строкой", v.Type())
+	}
+	return nil
+}
+
+// String форматирует значение для Dump
+func (p parameter) String() string {
+	v := p.value
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Map {
+		if v.IsNil() {
+			return "null"
+		}
+	}
+	if v.Kind() == reflect.Pointer && v.Elem().Kind() != reflect.Struct {
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		return strings.Join(v.Interface().([]string), ",")
+	case v.Kind() == reflect.Map || v.Kind() == reflect.Pointer:
+		out, err := yaml.MarshalWithOptions(v.Interface(), yaml.Flow(true))
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return strings.TrimSpace(string(out))
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoadTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func TestLoadTestSuite(t *testing.T) {
+	suite.Run(t, new(LoadTestSuite))
+}
+
+func (s *LoadTestSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "config.yaml")
+	err := os.WriteFile(s.path, []byte(`
+env: dev
+grpc:
+  port: 8080
+  timeout: 5s
+prometheus:
+  port: 9090
+shutdown:
+  timeout: 10s
+admin:
+  token: from-file
+`), 0o600)
+	require.NoError(s.T(), err)
+}
+
+func (s *LoadTestSuite) TestLoad_Precedence() {
+	// Arrange
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.NoError(s.T(), fs.Parse([]string{"--grpc.port=7070", "--rate_limit.enabled"}))
+
+	// Act
+	loaded, err := Load(LoadOptions{
+		Path: s.path,
+		Environ: []string{
+			"NOTES_GRPC_PORT=6060",
+			"NOTES_SHUTDOWN_TIMEOUT=15s",
+			"NOTES_TRACING_PROPAGATORS=b3, tracecontext",
+		},
+		Flags: flags,
+	})
+	require.NoError(s.T(), err)
+	cfg := loaded.Config
+
+	// Assert
+	assert.Equal(s.T(), 7070, *cfg.GRPC.Port)
+	assert.True(s.T(), cfg.RateLimit.Enabled)
+	assert.Equal(s.T(), 15*time.Second, cfg.Shutdown.Timeout)
+	assert.Equal(s.T(), []string{"b3", "tracecontext"}, cfg.Tracing.Propagators)
+	assert.Equal(s.T(), 5*time.Second, *cfg.GRPC.Timeout)
+	assert.Equal(s.T(), 2*time.Second, cfg.Health.Timeout)
+
+	assert.Equal(s.T(), SourceFlag, loaded.Sources["grpc.port"])
+	assert.Equal(s.T(), SourceEnv, loaded.Sources["shutdown.timeout"])
+	assert.Equal(s.T(), SourceFile, loaded.Sources["grpc.timeout"])
+	assert.Equal(s.T(), SourceDefault, loaded.Sources["health.timeout"])
+}
+
+func (s *LoadTestSuite) TestLoad_Defaults() {
+	// Act
+	loaded, err := Load(LoadOptions{Environ: []string{"NOTES_GRPC_PORT=8080", "NOTES_GRPC_TIMEOUT=1s", "NOTES_PROMETHEUS_PORT=9090"}})
+	require.NoError(s.T(), err)
+	cfg := loaded.Config
+
+	// Assert
+	assert.Equal(s.T(), "local", cfg.Env)
+	assert.Equal(s.T(), LogFormatText, cfg.Logging.Format)
+	assert.Equal(s.T(), TLSModeInsecure, cfg.GRPC.TLS.Mode)
+	assert.Equal(s.T(), 1.0, *cfg.Tracing.SampleRatio)
+	assert.Equal(s.T(), []string{RateLimitKeyPrincipal}, cfg.RateLimit.KeyBy)
+	assert.Equal(s.T(), defaultTenantID, cfg.Tenancy.Default)
+	assert.Equal(s.T(), SourceDefault, loaded.Sources["logging.format"])
+}
+
+func (s *LoadTestSuite) TestLoad_InvalidEnv() {
+	// Act
+	_, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_GRPC_PORT=abc"}})
+
+	// Assert
+	assert.ErrorContains(s.T(), err, "NOTES_GRPC_PORT")
+}
+
+func (s *LoadTestSuite) TestDump_RedactsSecrets() {
+	// Arrange
+	loaded, err := Load(LoadOptions{Path: s.path})
+	require.NoError(s.T(), err)
+	var out bytes.Buffer
+
+	// Act
+	require.NoError(s.T(), loaded.Dump(&out))
+
+	// Assert
+	assert.Regexp(s.T(), `(?m)^grpc\.port\s+8080\s+file$`, out.String())
+	assert.Regexp(s.T(), `(?m)^admin\.token\s+\*\*\*\s+file$`, out.String())
+	assert.NotContains(s.T(), out.String(), "from-file")
+}
+
+func (s *LoadTestSuite) TestLoad_AggregatesValidationErrors() {
+	// Arrange
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(`
+grpc:
+  port: 8080
+  timeout: 0s
+  tsl: {}
+prometheus:
+  port: 8080
+rate_limit:
+  enabled: true
+  methods:
+    /notes.Notes/AddNote: {rps: 0, burst: 1}
+`), 0o600))
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_SHUTDOWN_DRAIN_DELAY=1m"}})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	locations := map[string]string{}
+	for _, e := range verr.Errors {
+		locations[e.Path] = e.Location
+	}
+	assert.Equal(s.T(), map[string]string{
+		"grpc.tsl":             s.path + ":5:3",
+		"grpc.timeout":         s.path + ":4:12",
+		"prometheus.port":      s.path + ":7:9",
+		"shutdown.drain_delay": "переменная NOTES_SHUTDOWN_DRAIN_DELAY",
+		"rate_limit.methods[/notes.Notes/AddNote].rps": s.path + ":11:33",
+	}, locations)
+}
+
+func (s *LoadTestSuite) TestLoad_AggregatesParseErrors() {
+	// Arrange
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(`
+grpc:
+  port: abc
+  timeout: 5s
+  method_timeouts:
+    /notes.Notes/AddNote: soon
+prometheus: 9090
+`), 0o600))
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.NoError(s.T(), fs.Parse([]string{"--shutdown.timeout=later"}))
+
+	// Act
+	_, err := Load(LoadOptions{
+		Path:    s.path,
+		Environ: []string{"NOTES_SHUTDOWN_DRAIN_DELAY=1x"},
+		Flags:   flags,
+	})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	locations := map[string]string{}
+	for _, e := range verr.Errors {
+		locations[e.Path] = e.Location
+	}
+	assert.Equal(s.T(), map[string]string{
+		"grpc.port": s.path + ":3:9",
+		"grpc.method_timeouts[/notes.Notes/AddNote]": s.path + ":6:27",
+		"prometheus":           s.path + ":7:13",
+		"shutdown.drain_delay": "переменная NOTES_SHUTDOWN_DRAIN_DELAY",
+		"shutdown.timeout":     "флаг --shutdown.timeout",
+	}, locations)
+}
+
+func (s *LoadTestSuite) TestLoad_InvalidMethodTimeouts() {
+	// Arrange
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(`
+grpc:
+  port: 8080
+  timeout: 5s
+  method_timeouts:
+    /notes.Notes/AddNote: 0s
+    AddNote: 1s
+`), 0o600))
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	paths := []string{}
+	for _, e := range verr.Errors {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(s.T(), []string{
+		"grpc.method_timeouts[/notes.Notes/AddNote]",
+		"grpc.method_timeouts[AddNote]",
+	}, paths)
+}
+
+func (s *LoadTestSuite) TestLoad_TransportDefaults() {
+	// Act
+	loaded, err := Load(LoadOptions{Environ: []string{"NOTES_GRPC_PORT=8080", "NOTES_GRPC_TIMEOUT=1s"}})
+	require.NoError(s.T(), err)
+	grpc := loaded.Config.GRPC
+
+	// Assert
+	assert.Equal(s.T(), time.Minute, grpc.Keepalive.Time)
+	assert.Equal(s.T(), 5*time.Minute, grpc.Keepalive.MaxConnectionAge)
+	assert.Equal(s.T(), 30*time.Second, grpc.Keepalive.MaxConnectionAgeGrace)
+	assert.Equal(s.T(), 10*time.Second, grpc.Keepalive.Enforcement.MinTime)
+	assert.True(s.T(), grpc.Keepalive.Enforcement.PermitWithoutStream)
+	assert.Equal(s.T(), 1000, grpc.MaxConcurrentStreams)
+	assert.Equal(s.T(), 4<<20, grpc.MaxRecvMsgSize)
+	assert.Zero(s.T(), grpc.InitialWindowSize)
+	assert.Equal(s.T(), 10*time.Second, grpc.ConnectionTimeout)
+}
+
+func (s *LoadTestSuite) TestLoad_InvalidTransport() {
+	// Arrange
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(`
+grpc:
+  port: 8080
+  timeout: 5s
+  keepalive:
+    time: 500ms
+    max_connection_age: -1s
+  max_concurrent_streams: 0
+  max_recv_msg_size: 0
+  initial_window_size: 1024
+  connection_timeout: 0s
+`), 0o600))
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	paths := []string{}
+	for _, e := range verr.Errors {
+		paths = append(paths, e.Path)
+	}
+	assert.ElementsMatch(s.T(), []string{
+		"grpc.keepalive.time",
+		"grpc.keepalive.max_connection_age",
+		"grpc.max_concurrent_streams",
+		"grpc.max_recv_msg_size",
+		"grpc.initial_window_size",
+		"grpc.connection_timeout",
+	}, paths)
+}
+
+func (s *LoadTestSuite) TestLoad_ZeroLimitsRejected() {
+	// Arrange
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(`
+grpc:
+  port: 8080
+  timeout: 5s
+limits:
+  max_notes: 0
+tenant_quota:
+  max_bytes: 0
+`), 0o600))
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	paths := []string{}
+	for _, e := range verr.Errors {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(s.T(), []string{"limits.max_notes", "tenant_quota.max_bytes"}, paths)
+}
+
+func (s *LoadTestSuite) TestLoad_AdminHTTPAddrLoopbackOnly() {
+	// Arrange
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(`
+grpc:
+  port: 8080
+  timeout: 5s
+admin:
+  enabled: true
+  token: secret
+  http_addr: 0.0.0.0:9091
+`), 0o600))
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	require.Len(s.T(), verr.Errors, 1)
+	assert.Equal(s.T(), "admin.http_addr", verr.Errors[0].Path)
+}
+
+func (s *LoadTestSuite) TestLoad_ShippedConfig() {
+	// Act
+	loaded, err := Load(LoadOptions{Path: "../../configs/config.yaml"})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.NotNil(s.T(), loaded.Config.Prometheus.Port)
+}
+
+func (s *LoadTestSuite) TestLoad_PrometheusPortDefault() {
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 9090, *loaded.Config.Prometheus.Port)
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// Окружения, они же профили конфигурации
+const (
+	EnvLocal   = "local"
+	EnvDev     = "dev"
+	EnvStaging = "staging"
+	EnvProd    = "prod"
+)
+
+// includeKey - ключ YAML со списком включаемых файлов. Пути задаются
+// относительно включающего файла, включения применяются перед ним.
+const includeKey = "include"
+
+// layer - YAML файл конфигурации
+type layer struct {
+	path string
+	raw  []byte
+	doc  ast.Node // тело документа без ключа include
+	data map[string]any
+}
+
+// readLayers читает базовый файл и overlay профиля вместе с их
+// включениями в порядке применения. Профиль берется из флага --env,
+// переменной NOTES_ENV или ключа env базового файла. Overlay для профиля
+// prod и файла configs/config.yaml - configs/config.prod.yaml, его
+// отсутствие не является ошибкой.
+func readLayers(opts LoadOptions, environ map[string]string) ([]layer, string, error) {
+	layers, err := readFile(opts.Path, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	profile, err := selectProfile(opts, environ, layers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	overlay := overlayPath(opts.Path, profile)
+	if _, err := os.Stat(overlay); err == nil {
+		overlays, err := readFile(overlay, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		layers = append(layers, overlays...)
+	}
+	return layers, profile, nil
+}
+
+// selectProfile возвращает профиль по приоритету: флаг, переменная
+// окружения, базовый файл, env-default
+func selectProfile(opts LoadOptions, environ map[string]string, base []layer) (string, error) {
+	if opts.Flags != nil {
+		if profile, ok := opts.Flags.values["env"]; ok {
+			return profile, nil
+		}
+	}
+	if profile, ok := environ[envName("env")]; ok {
+		return profile, nil
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		value, ok := base[i].data["env"]
+		if !ok {
+			continue
+		}
+		profile, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%s: env должен быть строкой", base[i].path)
+		}
+		return profile, nil
+	}
+	return EnvLocal, nil
+}
+
+// overlayPath возвращает путь overlay профиля: config.yaml -> config.prod.yaml
+func overlayPath(base, profile string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + profile + ext
+}
+
+// readFile читает файл и рекурсивно его включения. stack - цепочка
+// включающих файлов для обнаружения циклов.
+func readFile(path string, stack []string) ([]layer, error) {
+	for _, p := range stack {
+		if p == path {
+			return nil, fmt.Errorf("циклическое включение: %s -> %s", strings.Join(stack, " -> "), path)
+		}
+	}
+	stack = append(stack, path)
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	file, err := parser.ParseBytes(raw, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var doc ast.Node
+	if len(file.Docs) > 0 {
+		doc = file.Docs[0].Body
+	}
+
+	includes, err := includeList(data[includeKey])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	delete(data, includeKey)
+
+	var layers []layer
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := readFile(include, stack)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, included...)
+	}
+	return append(layers, layer{path: path, raw: raw, doc: withoutKey(doc, includeKey), data: data}), nil
+}
+
+// includeList разбирает значение include: строку или список строк
+func includeList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		includes := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("include должен содержать пути к файлам, задано %v", item)
+			}
+			includes = append(includes, s)
+		}
+		return includes, nil
+	}
+	return nil, fmt.Errorf("include должен быть путем или списком путей, задано %v", value)
+}
+
+// withoutKey возвращает словарь YAML без ключа верхнего уровня key
+func withoutKey(node ast.Node, key string) ast.Node {
+	values := mappingValues(node)
+	if values == nil {
+		return node
+	}
+	filtered := make([]*ast.MappingValueNode, 0, len(values))
+	for _, kv := range values {
+		if kv.Key.GetToken().Value != key {
+			filtered = append(filtered, kv)
+		}
+	}
+	return &ast.MappingNode{Values: filtered}
+}
+
+// mergeLayers сливает данные файлов: словари объединяются рекурсивно,
+// остальные значения, включая списки, заменяются значением из более
+// позднего файла
+func mergeLayers(layers []layer) map[string]any {
+	merged := map[string]any{}
+	for _, l := range layers {
+		mergeMaps(merged, l.data)
+	}
+	return merged
+}
+
+func mergeMaps(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]any)
+		dstMap, dstOK := dst[key].(map[string]any)
+		if srcOK && dstOK {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		if srcOK {
+			// Копия, чтобы следующие файлы не изменяли данные этого
+			copied := map[string]any{}
+			mergeMaps(copied, srcMap)
+			value = copied
+		}
+		dst[key] = value
+	}
+}
+
+// deletePath удаляет из данных файла значение по ключам
+func deletePath(data map[string]any, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := data[key].(map[string]any)
+		if !ok {
+			return
+		}
+		data = next
+	}
+	delete(data, keys[len(keys)-1])
+}
+
+// Render выводит итоговую конфигурацию в YAML. Значения секретов
+// скрываются, как и в Dump.
+func (l *Loaded) Render(w io.Writer) error {
+	fmt.Fprintf(w, "# профиль: %s\n", l.Config.Env)
+	for _, file := range l.Files {
+		fmt.Fprintf(w, "# файл: %s\n", file)
+	}
+	out, err := yaml.Marshal(l.Config)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
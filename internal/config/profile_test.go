@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const profileBase = `
+env: local
+grpc:
+  port: 8080
+  timeout: 5s
+logging:
+  level: debug
+  format: text
+rate_limit:
+  key_by: [principal, tenant]
+  methods:
+    /notes.Notes/AddNote: {rps: 10, burst: 20}
+features:
+  search: false
+  export: true
+admin:
+  token: plain-token
+`
+
+type ProfileTestSuite struct {
+	suite.Suite
+	dir  string
+	path string
+}
+
+func TestProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileTestSuite))
+}
+
+func (s *ProfileTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.path = s.write("config.yaml", profileBase)
+}
+
+func (s *ProfileTestSuite) write(name, data string) string {
+	path := filepath.Join(s.dir, name)
+	require.NoError(s.T(), os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(s.T(), os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func (s *ProfileTestSuite) TestLoad_DeepMergesOverlay() {
+	// Arrange
+	overlay := s.write("config.prod.yaml", `
+logging:
+  level: info
+rate_limit:
+  key_by: [tenant]
+  methods:
+    /notes.Notes/GetNote: {rps: 50, burst: 100}
+features:
+  search: true
+`)
+
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_ENV=prod"}})
+	require.NoError(s.T(), err)
+	cfg := loaded.Config
+
+	// Assert
+	assert.Equal(s.T(), EnvProd, cfg.Env)
+	assert.Equal(s.T(), "info", cfg.Logging.Level)
+	assert.Equal(s.T(), LogFormatText, cfg.Logging.Format, "значение базового файла сохраняется")
+	assert.Equal(s.T(), []string{"tenant"}, cfg.RateLimit.KeyBy, "списки заменяются целиком")
+	assert.Len(s.T(), cfg.RateLimit.Methods, 2, "словари объединяются")
+	assert.Equal(s.T(), map[string]bool{"search": true, "export": true}, cfg.Features)
+	assert.Equal(s.T(), []string{s.path, overlay}, loaded.Files)
+}
+
+func (s *ProfileTestSuite) TestLoad_ProfileFromFlag() {
+	// Arrange
+	s.write("config.dev.yaml", "logging:\n  level: warn\n")
+	s.write("config.prod.yaml", "logging:\n  level: error\n")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.NoError(s.T(), fs.Parse([]string{"--env=dev"}))
+
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_ENV=prod"}, Flags: flags})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), EnvDev, loaded.Config.Env)
+	assert.Equal(s.T(), "warn", loaded.Config.Logging.Level)
+}
+
+func (s *ProfileTestSuite) TestLoad_WithoutOverlay() {
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_ENV=staging"}})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), EnvStaging, loaded.Config.Env)
+	assert.Equal(s.T(), []string{s.path}, loaded.Files)
+}
+
+func (s *ProfileTestSuite) TestLoad_Includes() {
+	// Arrange
+	shared := s.write("include/shared.yaml", `
+include: [limits.yaml]
+logging:
+  level: warn
+grpc:
+  timeout: 7s
+`)
+	limits := s.write("include/limits.yaml", "limits:\n  max_notes: 42\n")
+	overlay := s.write("config.dev.yaml", `
+include: include/shared.yaml
+grpc:
+  timeout: 9s
+`)
+
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_ENV=dev"}})
+	require.NoError(s.T(), err)
+	cfg := loaded.Config
+
+	// Assert
+	assert.Equal(s.T(), "warn", cfg.Logging.Level)
+	assert.Equal(s.T(), 42, *cfg.Limits.MaxNotes)
+	assert.Equal(s.T(), 9*time.Second, *cfg.GRPC.Timeout, "файл переопределяет свои включения")
+	assert.Equal(s.T(), []string{s.path, limits, shared, overlay}, loaded.Files)
+}
+
+func (s *ProfileTestSuite) TestLoad_IncludeCycle() {
+	// Arrange
+	s.write("a.yaml", "include: b.yaml\n")
+	s.write("b.yaml", "include: a.yaml\n")
+	s.write("config.yaml", profileBase+"include: a.yaml\n")
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	assert.ErrorContains(s.T(), err, "циклическое включение")
+}
+
+func (s *ProfileTestSuite) TestLoad_ErrorLocatedInOverlay() {
+	// Arrange
+	overlay := s.write("config.prod.yaml", `
+grpc:
+  timeout: 0s
+  unknown: 1
+`)
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path, Environ: []string{"NOTES_ENV=prod"}})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	locations := map[string]string{}
+	for _, e := range verr.Errors {
+		locations[e.Path] = e.Location
+	}
+	assert.Equal(s.T(), map[string]string{
+		"grpc.timeout": overlay + ":3:12",
+		"grpc.unknown": overlay + ":4:3",
+	}, locations)
+}
+
+func (s *ProfileTestSuite) TestLoad_UnknownEnv() {
+	// Arrange
+	s.write("config.yaml", "env: debug\ngrpc:\n  port: 8080\n  timeout: 5s\n")
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	require.Len(s.T(), verr.Errors, 1)
+	assert.Equal(s.T(), "env", verr.Errors[0].Path)
+	assert.Equal(s.T(), s.path+":1:6", verr.Errors[0].Location)
+}
+
+func (s *ProfileTestSuite) TestRender_RedactsSecrets() {
+	// Arrange
+	loaded, err := Load(LoadOptions{Path: s.path})
+	require.NoError(s.T(), err)
+	var out bytes.Buffer
+
+	// Act
+	require.NoError(s.T(), loaded.Render(&out))
+
+	// Assert
+	assert.Contains(s.T(), out.String(), "# профиль: local\n")
+	assert.Contains(s.T(), out.String(), "timeout: 5s\n")
+	assert.Contains(s.T(), out.String(), `token: "***"`)
+	assert.NotContains(s.T(), out.String(), "plain-token")
+}
+
+func (s *ProfileTestSuite) TestWatcher_TracksOverlay() {
+	// Arrange
+	overlay := s.write("config.prod.yaml", "logging:\n  level: info\n")
+	opts := LoadOptions{Path: s.path, Environ: []string{"NOTES_ENV=prod"}}
+	loaded, err := Load(opts)
+	require.NoError(s.T(), err)
+	watcher := NewWatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), opts, loaded.Config, nil)
+	later := time.Now().Add(time.Minute)
+
+	// Act
+	require.NoError(s.T(), os.Chtimes(overlay, later, later))
+
+	// Assert
+	assert.Equal(s.T(), overlay, watcher.fileChanged())
+}
+
+func (s *ProfileTestSuite) TestLoad_ShippedProfiles() {
+	for _, env := range []string{EnvLocal, EnvDev, EnvStaging, EnvProd} {
+		// Act
+		loaded, err := Load(LoadOptions{Path: "../../configs/config.yaml", Environ: []string{"NOTES_ENV=" + env}})
+
+		// Assert
+		require.NoError(s.T(), err, env)
+		assert.Equal(s.T(), env, loaded.Config.Env)
+	}
+}
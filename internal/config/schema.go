@@ -0,0 +1,130 @@
+package config
+
+//go:generate go run ./docgen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// durationPattern - формат time.ParseDuration: 300ms, 1m30s, 0
+const durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+// Schema возвращает JSON Schema (draft 2020-12) YAML файла конфигурации.
+// Типы, обязательные поля, значения по умолчанию и допустимые значения
+// берутся из структуры Config и тегов env-required, env-default и enum,
+// описания - из комментариев полей (см. docs_gen.go). Неизвестные ключи
+// запрещены, как и при загрузке.
+func Schema() ([]byte, error) {
+	root := schemaOf(reflect.TypeFor[Config]())
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = "Конфигурация notes_service"
+	root["properties"].(map[string]any)[includeKey] = map[string]any{
+		"description": "файлы, применяемые перед этим файлом; пути относительно него",
+		"anyOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaOf возвращает схему значения типа t
+func schemaOf(t reflect.Type) map[string]any {
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	case t == reflect.TypeFor[Secret]():
+		return map[string]any{
+			"type": "string",
+			"$comment": "значение или ссылка " + SecretSchemeFile + ", " +
+				SecretSchemeEnv + " или " + SecretSchemeVault,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := schemaOf(t.Elem())
+		s["type"] = []any{s["type"], "null"}
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]any{}
+}
+
+// structSchema возвращает схему структуры с полями по тегам yaml
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		s := schemaOf(field.Type)
+		if doc, ok := fieldDocs[t.Name()+"."+field.Name]; ok {
+			s["description"] = doc
+		}
+		if def := field.Tag.Get("env-default"); def != "" {
+			s["default"] = defaultValue(field.Type, def)
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := strings.Split(enum, ",")
+			if items, ok := s["items"].(map[string]any); ok {
+				items["enum"] = values
+			} else {
+				s["enum"] = values
+			}
+		}
+		// Секция с обязательными полями обязательна сама
+		_, nested := s["required"]
+		if field.Tag.Get("env-required") == "true" || nested && field.Type.Kind() == reflect.Struct {
+			required = append(required, name)
+		}
+		properties[name] = s
+	}
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// defaultValue разбирает тег env-default так же, как Load,
+// и возвращает значение для JSON
+func defaultValue(t reflect.Type, def string) any {
+	v := reflect.New(t).Elem()
+	if err := (parameter{value: v}).set(def); err != nil {
+		return def
+	}
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		return def
+	}
+	return v.Interface()
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaTestSuite struct {
+	suite.Suite
+	schema *jsonschema.Schema
+}
+
+func TestSchemaTestSuite(t *testing.T) {
+	suite.Run(t, new(SchemaTestSuite))
+}
+
+func (s *SchemaTestSuite) SetupTest() {
+	data, err := Schema()
+	require.NoError(s.T(), err)
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	require.NoError(s.T(), err)
+
+	c := jsonschema.NewCompiler()
+	require.NoError(s.T(), c.AddResource("config.schema.json", doc))
+	s.schema, err = c.Compile("config.schema.json")
+	require.NoError(s.T(), err)
+}
+
+// validate проверяет YAML документ по схеме
+func (s *SchemaTestSuite) validate(data []byte) error {
+	js, err := yaml.YAMLToJSON(data)
+	require.NoError(s.T(), err)
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(js))
+	require.NoError(s.T(), err)
+	return s.schema.Validate(inst)
+}
+
+func (s *SchemaTestSuite) TestSchema_ShippedConfig() {
+	// Arrange
+	data, err := os.ReadFile("../../configs/config.yaml")
+	require.NoError(s.T(), err)
+
+	// Act
+	err = s.validate(data)
+
+	// Assert
+	assert.NoError(s.T(), err)
+}
+
+func (s *SchemaTestSuite) TestSchema_RenderedProfiles() {
+	for _, env := range []string{EnvLocal, EnvDev, EnvStaging, EnvProd} {
+		// Arrange
+		loaded, err := Load(LoadOptions{Path: "../../configs/config.yaml", Environ: []string{"NOTES_ENV=" + env}})
+		require.NoError(s.T(), err, env)
+		var out bytes.Buffer
+		require.NoError(s.T(), loaded.Render(&out))
+
+		// Act
+		err = s.validate(out.Bytes())
+
+		// Assert
+		assert.NoError(s.T(), err, env)
+	}
+}
+
+func (s *SchemaTestSuite) TestSchema_RejectsInvalidConfig() {
+	cases := map[string]string{
+		"неизвестный env":      "env: debug\ngrpc: {port: 8080, timeout: 5s}\n",
+		"неизвестный ключ":     "grpc: {port: 8080, timeout: 5s, tsl: {}}\n",
+		"нет grpc.port":        "grpc: {timeout: 5s}\n",
+		"нет секции grpc":      "env: dev\n",
+		"длительность":         "grpc: {port: 8080, timeout: 5}\nhealth: {interval: soon}\n",
+		"элемент списка":       "grpc: {port: 8080, timeout: 5s}\ntracing: {propagators: [w3c]}\n",
+		"тип значения словаря": "grpc: {port: 8080, timeout: 5s}\nfeatures: {search: maybe}\n",
+	}
+	for name, data := range cases {
+		// Act
+		err := s.validate([]byte(data))
+
+		// Assert
+		assert.Error(s.T(), err, name)
+	}
+}
+
+func (s *SchemaTestSuite) TestSchema_DescribesParameters() {
+	// Arrange
+	data, err := Schema()
+	require.NoError(s.T(), err)
+	var root struct {
+		Properties map[string]struct {
+			Default     any      `json:"default"`
+			Enum        []string `json:"enum"`
+			Description string   `json:"description"`
+			Required    []string `json:"required"`
+			Properties  map[string]struct {
+				Default any      `json:"default"`
+				Enum    []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+
+	// Act
+	require.NoError(s.T(), json.Unmarshal(data, &root))
+
+	// Assert
+	env := root.Properties["env"]
+	assert.Equal(s.T(), "local", env.Default)
+	assert.Equal(s.T(), []string{EnvLocal, EnvDev, EnvStaging, EnvProd}, env.Enum)
+	assert.NotEmpty(s.T(), env.Description)
+	assert.Equal(s.T(), []string{"grpc"}, root.Required)
+	assert.Equal(s.T(), []string{"port", "timeout"}, root.Properties["grpc"].Required)
+	assert.Equal(s.T(), "10s", root.Properties["health"].Properties["interval"].Default)
+	assert.Equal(s.T(), 9090.0, root.Properties["prometheus"].Properties["port"].Default)
+	assert.Equal(s.T(), []any{"tracecontext", "baggage"}, root.Properties["tracing"].Properties["propagators"].Default)
+	assert.Equal(s.T(), []string{"debug", "info", "warn", "error"}, root.Properties["logging"].Properties["level"].Enum)
+}
+
+func (s *SchemaTestSuite) TestFieldDocs_OnlyConfigTypes() {
+	// Arrange: структуры, достижимые из Config
+	reachable := map[string]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || reachable[t.Name()] {
+			return
+		}
+		reachable[t.Name()] = true
+		for i := range t.NumField() {
+			walk(t.Field(i).Type)
+		}
+	}
+	walk(reflect.TypeFor[Config]())
+
+	// Act
+	var foreign []string
+	for key := range fieldDocs {
+		typeName, _, _ := strings.Cut(key, ".")
+		if !reachable[typeName] {
+			foreign = append(foreign, key)
+		}
+	}
+
+	// Assert
+	assert.Empty(s.T(), foreign)
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Схемы ссылок на секреты в значениях типа Secret
+const (
+	SecretSchemeFile  = "file://"  // file:///run/secrets/token - содержимое файла
+	SecretSchemeEnv   = "env://"   // env://ADMIN_TOKEN - переменная окружения
+	SecretSchemeVault = "vault://" // vault://secret/notes#admin_token - ключ Vault KV v2
+)
+
+// Secret - значение, которое не выводится в логи и дампы конфигурации.
+// В конфигурации задается явно или ссылкой file://, env:// или vault://,
+// которая разрешается при загрузке и обновляется каждые
+// secrets.refresh_interval.
+type Secret string
+
+// Value возвращает значение секрета
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String скрывает значение при форматировании через fmt
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "***"
+}
+
+// LogValue скрывает значение в slog
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalYAML скрывает значение при выводе конфигурации в YAML
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+// MarshalJSON скрывает значение при выводе в JSON
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// MarshalText скрывает значение в кодировщиках, использующих
+// encoding.TextMarshaler
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// SecretsConfig - источники секретов
+type SecretsConfig struct {
+	// RefreshInterval - период повторного чтения секретов; 0 - только при загрузке
+	RefreshInterval time.Duration `yaml:"refresh_interval" env-default:"1m"`
+	Vault           VaultConfig   `yaml:"vault"`
+}
+
+// VaultConfig - подключение к Vault KV v2 для ссылок vault://
+type VaultConfig struct {
+	Addr      string        `yaml:"addr"`                // например http://127.0.0.1:8200
+	Token     Secret        `yaml:"token" reload:"true"` // обычно env://VAULT_TOKEN
+	Namespace string        `yaml:"namespace"`           // Vault Enterprise
+	Timeout   time.Duration `yaml:"timeout" env-default:"5s"`
+	// DevFile - YAML файл, заменяющий Vault при локальной разработке:
+	// ключи верхнего уровня - пути mount/path, значения - словари секретов
+	DevFile string `yaml:"dev_file"`
+}
+
+// isSecretRef сообщает, является ли значение ссылкой на секрет
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretSchemeFile) ||
+		strings.HasPrefix(value, SecretSchemeEnv) ||
+		strings.HasPrefix(value, SecretSchemeVault)
+}
+
+// secretResolver получает значения по ссылкам на секреты
+type secretResolver struct {
+	environ map[string]string
+	vault   vaultKV // nil, если Vault не настроен
+	// cache - прочитанные секреты Vault по пути, чтобы не запрашивать
+	// один путь для каждого ключа
+	cache map[string]map[string]any
+}
+
+// vaultKV читает секреты по пути mount/path
+type vaultKV interface {
+	Read(ctx context.Context, mount, path string) (map[string]any, error)
+}
+
+// resolveSecrets заменяет ссылки во всех полях типа Secret на значения
+// и возвращает ссылки по путям параметров. Ошибки добавляются в v.
+// Токен Vault разрешается первым и сам не может ссылаться на Vault.
+func resolveSecrets(cfg *Config, environ map[string]string, v *validator) map[string]string {
+	refs := map[string]string{}
+	r := &secretResolver{environ: environ, cache: map[string]map[string]any{}}
+
+	vault := &cfg.Secrets.Vault
+	if ref := vault.Token.Value(); isSecretRef(ref) {
+		value, err := r.resolve(ref)
+		if err != nil {
+			v.add("secrets.vault.token", "не удалось получить секрет %s: %v", ref, err)
+		}
+		vault.Token = Secret(value)
+		refs["secrets.vault.token"] = ref
+	}
+	switch {
+	case vault.DevFile != "":
+		r.vault = devVault{file: vault.DevFile}
+	case vault.Addr != "":
+		r.vault = &httpVault{
+			addr:      strings.TrimRight(vault.Addr, "/"),
+			token:     vault.Token.Value(),
+			namespace: vault.Namespace,
+			client:    &http.Client{Timeout: vault.Timeout},
+		}
+	}
+
+	secretType := reflect.TypeFor[Secret]()
+	for _, p := range parameters(reflect.ValueOf(cfg).Elem(), "") {
+		if p.path == "secrets.vault.token" {
+			continue
+		}
+
+		switch {
+		case p.value.Type() == secretType:
+			ref := p.value.String()
+			if !isSecretRef(ref) {
+				continue
+			}
+			value, err := r.resolve(ref)
+			if err != nil {
+				v.add(p.path, "не удалось получить секрет %s: %v", ref, err)
+			}
+			p.value.SetString(value)
+			refs[p.path] = ref
+
+		case p.value.Kind() == reflect.Map && p.value.Type().Elem() == secretType && !p.value.IsNil():
+			// Значения словаря копируются, чтобы не изменять разобранный YAML
+			resolved := reflect.MakeMapWithSize(p.value.Type(), p.value.Len())
+			for _, key := range p.value.MapKeys() {
+				ref := p.value.MapIndex(key).String()
+				if isSecretRef(ref) {
+					value, err := r.resolve(ref)
+					if err != nil {
+						v.at(p.path).key(key.String()).add("", "не удалось получить секрет %s: %v", ref, err)
+					}
+					refs[p.path+"["+key.String()+"]"] = ref
+					ref = value
+				}
+				resolved.SetMapIndex(key, reflect.ValueOf(Secret(ref)))
+			}
+			p.value.Set(resolved)
+		}
+	}
+	return refs
+}
+
+func (r *secretResolver) resolve(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, SecretSchemeEnv):
+		name := strings.TrimPrefix(ref, SecretSchemeEnv)
+		value, ok := r.environ[name]
+		if !ok {
+			return "", fmt.Errorf("переменная %s не задана", name)
+		}
+		return value, nil
+
+	case strings.HasPrefix(ref, SecretSchemeFile):
+		data, err := os.ReadFile(strings.TrimPrefix(ref, SecretSchemeFile))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case strings.HasPrefix(ref, SecretSchemeVault):
+		return r.resolveVault(strings.TrimPrefix(ref, SecretSchemeVault))
+	}
+	return "", fmt.Errorf("неизвестная схема ссылки")
+}
+
+// resolveVault получает ключ по ссылке mount/path#key
+func (r *secretResolver) resolveVault(ref string) (string, error) {
+	location, key, ok := strings.Cut(ref, "#")
+	mount, path, hasPath := strings.Cut(location, "/")
+	if !ok || key == "" || !hasPath || mount == "" || path == "" {
+		return "", fmt.Errorf("ожидается ссылка вида vault://mount/path#key")
+	}
+	if r.vault == nil {
+		return "", fmt.Errorf("не задан secrets.vault.addr или secrets.vault.dev_file")
+	}
+
+	data, ok := r.cache[location]
+	if !ok {
+		ctx := context.Background()
+		var err error
+		if data, err = r.vault.Read(ctx, mount, path); err != nil {
+			return "", err
+		}
+		r.cache[location] = data
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("ключ %q не найден в %s", key, location)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// httpVault читает секреты через HTTP API Vault KV v2
+type httpVault struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func (h *httpVault) Read(ctx context.Context, mount, path string) (map[string]any, error) {
+	endpoint := h.addr + "/v1/" + escapePath(mount) + "/data/" + escapePath(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", h.token)
+	if h.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", h.namespace)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("запрос к Vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Vault ответил %s для %s/%s", resp.Status, mount, path)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("ответ Vault: %w", err)
+	}
+	return body.Data.Data, nil
+}
+
+// escapePath экранирует каждый сегмент пути, чтобы символы вроде ? и %
+// из ссылки не меняли запрос к Vault
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// devVault - локальная замена Vault: секреты читаются из YAML файла
+type devVault struct {
+	file string
+}
+
+func (d devVault) Read(_ context.Context, mount, path string) (map[string]any, error) {
+	data, err := os.ReadFile(d.file)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets map[string]map[string]any
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", d.file, err)
+	}
+	values, ok := secrets[mount+"/"+path]
+	if !ok {
+		return nil, fmt.Errorf("путь %s/%s не найден в %s", mount, path, d.file)
+	}
+	return values, nil
+}
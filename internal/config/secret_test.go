@@ -0,0 +1,232 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type SecretTestSuite struct {
+	suite.Suite
+	dir  string
+	path string
+}
+
+func TestSecretTestSuite(t *testing.T) {
+	suite.Run(t, new(SecretTestSuite))
+}
+
+func (s *SecretTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.path = filepath.Join(s.dir, "config.yaml")
+}
+
+func (s *SecretTestSuite) write(name, data string) string {
+	path := filepath.Join(s.dir, name)
+	require.NoError(s.T(), os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func (s *SecretTestSuite) config(body string) {
+	s.write("config.yaml", "grpc:\n  port: 8080\n  timeout: 5s\n"+body)
+}
+
+func (s *SecretTestSuite) TestLoad_ResolvesFileAndEnvRefs() {
+	// Arrange
+	token := s.write("token", "file-token\n")
+	s.config(`
+admin:
+  token: file://` + token + `
+rate_limit:
+  redis:
+    password: env://REDIS_PASSWORD
+tracing:
+  headers:
+    authorization: env://OTLP_AUTH
+    x-static: plain
+`)
+
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"REDIS_PASSWORD=redis-secret", "OTLP_AUTH=Bearer abc"}})
+	require.NoError(s.T(), err)
+	cfg := loaded.Config
+
+	// Assert
+	assert.Equal(s.T(), "file-token", cfg.Admin.Token.Value())
+	assert.Equal(s.T(), "redis-secret", cfg.RateLimit.Redis.Password.Value())
+	assert.Equal(s.T(), map[string]Secret{"authorization": "Bearer abc", "x-static": "plain"}, cfg.Tracing.Headers)
+	assert.Equal(s.T(), "env://REDIS_PASSWORD", loaded.Refs["rate_limit.redis.password"])
+	assert.Equal(s.T(), "env://OTLP_AUTH", loaded.Refs["tracing.headers[authorization]"])
+}
+
+func (s *SecretTestSuite) TestLoad_ResolvesVaultDevFile() {
+	// Arrange
+	dev := s.write("vault.yaml", `
+secret/notes:
+  admin_token: vault-token
+`)
+	s.config(`
+admin:
+  token: vault://secret/notes#admin_token
+secrets:
+  vault:
+    dev_file: ` + dev + `
+`)
+
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "vault-token", loaded.Config.Admin.Token.Value())
+}
+
+func (s *SecretTestSuite) TestLoad_ResolvesVaultHTTP() {
+	// Arrange
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Path != "/v1/secret/data/notes" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"data": map[string]any{"admin_token": "http-token", "redis": "http-redis"}},
+		})
+	}))
+	defer server.Close()
+	s.config(`
+admin:
+  token: vault://secret/notes#admin_token
+rate_limit:
+  redis:
+    password: vault://secret/notes#redis
+secrets:
+  vault:
+    addr: ` + server.URL + `
+    token: env://VAULT_TOKEN
+    namespace: team
+`)
+
+	// Act
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"VAULT_TOKEN=root"}})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "http-token", loaded.Config.Admin.Token.Value())
+	assert.Equal(s.T(), "http-redis", loaded.Config.RateLimit.Redis.Password.Value())
+	require.Len(s.T(), requests, 1, "путь читается из Vault один раз")
+	assert.Equal(s.T(), "root", requests[0].Header.Get("X-Vault-Token"))
+	assert.Equal(s.T(), "team", requests[0].Header.Get("X-Vault-Namespace"))
+}
+
+func (s *SecretTestSuite) TestLoad_EscapesVaultPath() {
+	// Arrange
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"data": map[string]any{"admin_token": "http-token"}},
+		})
+	}))
+	defer server.Close()
+	s.config(`
+admin:
+  token: vault://secret/team/my notes?version=1#admin_token
+secrets:
+  vault:
+    addr: ` + server.URL + `
+`)
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"/v1/secret/data/team/my%20notes%3Fversion=1"}, paths)
+}
+
+func (s *SecretTestSuite) TestSecret_RedactedInJSONAndText() {
+	// Arrange
+	secret := Secret("top-secret")
+
+	// Act
+	data, err := json.Marshal(struct {
+		Token Secret `json:"token"`
+	}{Token: secret})
+	require.NoError(s.T(), err)
+	text, err := secret.MarshalText()
+	require.NoError(s.T(), err)
+
+	// Assert
+	assert.JSONEq(s.T(), `{"token":"***"}`, string(data))
+	assert.Equal(s.T(), "***", string(text))
+}
+
+func (s *SecretTestSuite) TestLoad_UnresolvedRef() {
+	// Arrange
+	s.config(`
+admin:
+  token: env://MISSING_TOKEN
+`)
+
+	// Act
+	_, err := Load(LoadOptions{Path: s.path})
+
+	// Assert
+	var verr *ValidationError
+	require.ErrorAs(s.T(), err, &verr)
+	require.Len(s.T(), verr.Errors, 1)
+	assert.Equal(s.T(), "admin.token", verr.Errors[0].Path)
+	assert.Equal(s.T(), s.path+":6:10", verr.Errors[0].Location)
+	assert.Contains(s.T(), verr.Errors[0].Message, "MISSING_TOKEN")
+}
+
+func (s *SecretTestSuite) TestDump_ShowsRefInsteadOfValue() {
+	// Arrange
+	s.config(`
+admin:
+  token: env://ADMIN_TOKEN
+`)
+	loaded, err := Load(LoadOptions{Path: s.path, Environ: []string{"ADMIN_TOKEN=top-secret"}})
+	require.NoError(s.T(), err)
+	var out bytes.Buffer
+
+	// Act
+	require.NoError(s.T(), loaded.Dump(&out))
+
+	// Assert
+	assert.Regexp(s.T(), `(?m)^admin\.token\s+\*\*\* \(env://ADMIN_TOKEN\)\s+file$`, out.String())
+	assert.NotContains(s.T(), out.String(), "top-secret")
+}
+
+func (s *SecretTestSuite) TestWatcher_AppliesRotatedSecret() {
+	// Arrange
+	token := s.write("token", "old")
+	s.config(`
+admin:
+  token: file://` + token + `
+`)
+	opts := LoadOptions{Path: s.path}
+	loaded, err := Load(opts)
+	require.NoError(s.T(), err)
+	watcher := NewWatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), opts, loaded.Config, nil)
+	s.write("token", "new")
+
+	// Act
+	err = watcher.Reload()
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "new", watcher.Current().Admin.Token.Value())
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// FieldError - ошибка в значении параметра конфигурации
+type FieldError struct {
+	// Path - путь параметра через точку; ключи словарей в квадратных
+	// скобках, например rate_limit.methods[/notes.Notes/AddNote].rps
+	Path string
+	// Location - где задано значение: файл со строкой, переменная
+	// окружения, флаг или значение по умолчанию
+	Location string
+	Message  string
+}
+
+func (e FieldError) Error() string {
+	if e.Location == "" {
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Path, e.Location, e.Message)
+}
+
+// ValidationError содержит все найденные ошибки конфигурации
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "некорректная конфигурация, ошибок: %d", len(e.Errors))
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// validator накапливает ошибки, добавляя к ним путь проверяемой секции
+type validator struct {
+	prefix string
+	errs   *[]FieldError
+}
+
+func newValidator() *validator {
+	return &validator{errs: &[]FieldError{}}
+}
+
+// at возвращает validator вложенной секции
+func (v *validator) at(name string) *validator {
+	return &validator{prefix: v.path(name), errs: v.errs}
+}
+
+// key возвращает validator элемента словаря
+func (v *validator) key(k string) *validator {
+	return &validator{prefix: v.prefix + "[" + k + "]", errs: v.errs}
+}
+
+func (v *validator) path(field string) string {
+	switch {
+	case field == "":
+		return v.prefix
+	case v.prefix == "":
+		return field
+	}
+	return v.prefix + "." + field
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	*v.errs = append(*v.errs, FieldError{Path: v.path(field), Message: fmt.Sprintf(format, args...)})
+}
+
+// addAt добавляет ошибку секции с позицией узла YAML в файле
+func (v *validator) addAt(node ast.Node, file, format string, args ...any) {
+	pos := node.GetToken().Position
+	*v.errs = append(*v.errs, FieldError{
+		Path:     v.path(""),
+		Location: fmt.Sprintf("%s:%d:%d", file, pos.Line, pos.Column),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// port проверяет, что порт задан и находится в допустимом диапазоне
+func (v *validator) port(field string, port *int) {
+	if port == nil {
+		v.add(field, "порт не задан")
+		return
+	}
+	if *port < 1 || *port > 65535 {
+		v.add(field, "порт %d вне диапазона 1-65535", *port)
+	}
+}
+
+func (v *validator) positive(field string, d time.Duration) {
+	if d <= 0 {
+		v.add(field, "должен быть положительным, задано %s", d)
+	}
+}
+
+func (v *validator) nonNegative(field string, d time.Duration) {
+	if d < 0 {
+		v.add(field, "не может быть отрицательным, задано %s", d)
+	}
+}
+
+// windowSize проверяет окно HTTP/2: 0 (автоматический подбор)
+// или значение от 65535 до 2^31-1
+func (v *validator) windowSize(field string, size int) {
+	if size != 0 && (size < 65535 || size > math.MaxInt32) {
+		v.add(field, "должен быть 0 или в диапазоне 65535-%d, задано %d", math.MaxInt32, size)
+	}
+}
+
+// oneOf проверяет, что значение входит в список допустимых
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, "неизвестное значение %q, ожидается одно из: %s", value, strings.Join(allowed, ", "))
+}
+
+func (v *validator) err() error {
+	if len(*v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: *v.errs}
+}
+
+// splitPath разбивает путь параметра на ключи YAML
+func splitPath(path string) []string {
+	var keys []string
+	for path != "" {
+		if path[0] == '[' {
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return append(keys, path[1:])
+			}
+			keys = append(keys, path[1:end])
+			path = strings.TrimPrefix(path[end+1:], ".")
+			continue
+		}
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			return append(keys, path)
+		}
+		keys = append(keys, path[:end])
+		path = strings.TrimPrefix(path[end:], ".")
+	}
+	return keys
+}
+
+// mappingValues возвращает пары ключ-значение узла YAML, если он является словарем
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
+
+// findNode возвращает узел со значением по ключам или nil
+func findNode(node ast.Node, keys []string) ast.Node {
+	for _, key := range keys {
+		var next ast.Node
+		for _, kv := range mappingValues(node) {
+			if kv.Key.GetToken().Value == key {
+				next = kv.Value
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// checkNode проверяет, что каждому ключу YAML соответствует поле
+// структуры t с таким тегом yaml, включая элементы словарей, и что
+// значения приводятся к типам полей. Возвращает пути значений с ошибкой
+// типа, чтобы исключить их при слиянии файлов.
+func checkNode(v *validator, node ast.Node, t reflect.Type, file string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, ok := node.(*ast.NullNode); ok || node == nil {
+		return nil
+	}
+
+	var invalid []string
+	switch {
+	case t.Kind() == reflect.Map || t.Kind() == reflect.Struct && t != reflect.TypeFor[time.Duration]():
+		values := mappingValues(node)
+		if values == nil {
+			v.addAt(node, file, "ожидается словарь параметров")
+			return []string{v.path("")}
+		}
+		if t.Kind() == reflect.Map {
+			for _, kv := range values {
+				invalid = append(invalid, checkNode(v.key(kv.Key.GetToken().Value), kv.Value, t.Elem(), file)...)
+			}
+			return invalid
+		}
+
+		fields := map[string]reflect.Type{}
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for _, kv := range values {
+			key := kv.Key.GetToken()
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				v.at(key.Value).addAt(kv.Key, file, "неизвестный параметр")
+				continue
+			}
+			invalid = append(invalid, checkNode(v.at(key.Value), kv.Value, fieldType, file)...)
+		}
+	default:
+		if err := yaml.NodeToValue(node, reflect.New(t).Interface()); err != nil {
+			// Сообщение без фрагмента файла: позиция уже есть в Location
+			detail := err.Error()
+			var yamlErr yaml.Error
+			if errors.As(err, &yamlErr) {
+				detail = yamlErr.GetMessage()
+			}
+			v.addAt(node, file, "некорректное значение %s: %s", node, detail)
+			invalid = append(invalid, v.path(""))
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ReloadReporter учитывает результаты перезагрузки в метриках
+type ReloadReporter interface {
+	ConfigReloaded(success bool)
+}
+
+// Watcher перечитывает конфигурацию по SIGHUP, при изменении файла
+// и каждые secrets.refresh_interval для обновления секретов.
+// Новая конфигурация проверяется целиком и публикуется подписчикам
+// неизменяемым снимком. Применяются только поля с тегом reload,
+// изменения остальных полей отклоняются с предупреждением
+// и вступают в силу после перезапуска.
+type Watcher struct {
+	log      *slog.Logger
+	opts     LoadOptions
+	interval time.Duration
+	refresh  time.Duration
+	reporter ReloadReporter
+
+	current atomic.Pointer[Config]
+
+	// mu сериализует перезагрузки и уведомление подписчиков
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	// modTimes - время модификации файлов конфигурации, включая
+	// включения и overlay профиля
+	modTimes map[string]time.Time
+}
+
+// NewWatcher создает Watcher с начальной конфигурацией cfg, загруженной из opts
+func NewWatcher(log *slog.Logger, opts LoadOptions, cfg *Config, reporter ReloadReporter) *Watcher {
+	w := &Watcher{
+		log:      log,
+		opts:     opts,
+		interval: cfg.Reload.Interval,
+		refresh:  cfg.Secrets.RefreshInterval,
+		reporter: reporter,
+	}
+	w.current.Store(cfg)
+	if opts.Path != "" {
+		if layers, _, err := readLayers(opts, environMap(opts.Environ)); err == nil {
+			w.track(layers)
+		}
+	}
+	return w
+}
+
+// Current возвращает действующий снимок конфигурации. Снимок
+// не изменяется, следующая перезагрузка публикует новый.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Subscribe регистрирует fn, которая вызывается с новым снимком
+// после каждой перезагрузки с изменениями
+func (w *Watcher) Subscribe(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Run обрабатывает SIGHUP, проверяет файл и обновляет секреты до отмены ctx
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if w.interval > 0 && w.opts.Path != "" {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var refresh <-chan time.Time
+	if w.refresh > 0 {
+		ticker := time.NewTicker(w.refresh)
+		defer ticker.Stop()
+		refresh = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.log.Info("Получен SIGHUP, перезагрузка конфигурации")
+			w.Reload()
+		case <-tick:
+			if path := w.fileChanged(); path != "" {
+				w.log.Info("Файл конфигурации изменен, перезагрузка", "path", path)
+				w.Reload()
+			}
+		case <-refresh:
+			w.Reload()
+		}
+	}
+}
+
+// Reload перечитывает конфигурацию и публикует снимок с изменениями
+// перезагружаемых полей. При ошибке загрузки или проверки действующая
+// конфигурация сохраняется.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	loaded, err := Load(w.opts)
+	if err != nil {
+		w.log.Error("Не удалось перезагрузить конфигурацию, действует предыдущая", "error", err)
+		w.report(false)
+		return err
+	}
+
+	// Набор файлов может измениться вместе с include
+	w.track(loaded.layers)
+
+	next, applied, rejected := merge(w.current.Load(), loaded.Config)
+	if len(rejected) > 0 {
+		w.log.Warn("Изменения не применяются без перезапуска", "fields", rejected)
+	}
+	w.report(true)
+	if len(applied) == 0 {
+		w.log.Debug("Конфигурация перезагружена без изменений")
+		return nil
+	}
+
+	w.current.Store(next)
+	for _, fn := range w.subscribers {
+		fn(next)
+	}
+	w.log.Info("Конфигурация перезагружена", "fields", applied)
+	return nil
+}
+
+func (w *Watcher) report(success bool) {
+	if w.reporter != nil {
+		w.reporter.ConfigReloaded(success)
+	}
+}
+
+// track запоминает время модификации файлов layers. Вызывается под mu
+// или до запуска Run.
+func (w *Watcher) track(layers []layer) {
+	w.modTimes = make(map[string]time.Time, len(layers))
+	for _, l := range layers {
+		if info, err := os.Stat(l.path); err == nil {
+			w.modTimes[l.path] = info.ModTime()
+		}
+	}
+}
+
+// fileChanged возвращает путь файла конфигурации, время модификации
+// которого изменилось с последней проверки, или пустую строку
+func (w *Watcher) fileChanged() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changed := ""
+	for path, modTime := range w.modTimes {
+		info, err := os.Stat(path)
+		if err != nil {
+			w.log.Warn("Не удалось проверить файл конфигурации", "path", path, "error", err)
+			continue
+		}
+		if !info.ModTime().Equal(modTime) {
+			w.modTimes[path] = info.ModTime()
+			changed = path
+		}
+	}
+	return changed
+}
+
+// merge возвращает копию current, в которой перезагружаемые поля взяты
+// из loaded, и пути примененных и отклоненных изменений
+func merge(current, loaded *Config) (next *Config, applied, rejected []string) {
+	next = new(Config)
+	*next = *current
+
+	target := parameters(reflect.ValueOf(next).Elem(), "")
+	before := parameters(reflect.ValueOf(current).Elem(), "")
+	after := parameters(reflect.ValueOf(loaded).Elem(), "")
+	for i, p := range after {
+		// Сравниваются исходные значения: String скрывает секреты
+		if reflect.DeepEqual(p.value.Interface(), before[i].value.Interface()) {
+			continue
+		}
+		if !p.reload {
+			rejected = append(rejected, p.path)
+			continue
+		}
+		target[i].value.Set(p.value)
+		applied = append(applied, p.path)
+	}
+	return next, applied, rejected
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const watcherConfig = `
+env: dev
+logging:
+  level: info
+grpc:
+  port: 8080
+  timeout: 5s
+limits:
+  max_notes: 10
+features:
+  search: false
+`
+
+// reloads запоминает результаты перезагрузок
+type reloads []bool
+
+func (r *reloads) ConfigReloaded(success bool) { *r = append(*r, success) }
+
+type WatcherTestSuite struct {
+	suite.Suite
+	path     string
+	reloads  reloads
+	watcher  *Watcher
+	received []*Config
+}
+
+func TestWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(WatcherTestSuite))
+}
+
+func (s *WatcherTestSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "config.yaml")
+	s.write(watcherConfig)
+
+	opts := LoadOptions{Path: s.path}
+	loaded, err := Load(opts)
+	require.NoError(s.T(), err)
+
+	s.reloads = nil
+	s.received = nil
+	s.watcher = NewWatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), opts, loaded.Config, &s.reloads)
+	s.watcher.Subscribe(func(cfg *Config) { s.received = append(s.received, cfg) })
+}
+
+func (s *WatcherTestSuite) write(data string) {
+	require.NoError(s.T(), os.WriteFile(s.path, []byte(data), 0o600))
+}
+
+func (s *WatcherTestSuite) TestReload_AppliesReloadableFields() {
+	// Arrange
+	initial := s.watcher.Current()
+	s.write(`
+env: dev
+logging:
+  level: debug
+grpc:
+  port: 8080
+  timeout: 5s
+limits:
+  max_notes: 20
+features:
+  search: true
+`)
+
+	// Act
+	err := s.watcher.Reload()
+
+	// Assert
+	require.NoError(s.T(), err)
+	current := s.watcher.Current()
+	assert.NotSame(s.T(), initial, current)
+	assert.Equal(s.T(), 20, *current.Limits.MaxNotes)
+	assert.True(s.T(), current.Features["search"])
+	assert.Equal(s.T(), "debug", current.Logging.Level)
+	// Предыдущий снимок не изменяется
+	assert.Equal(s.T(), 10, *initial.Limits.MaxNotes)
+	require.Len(s.T(), s.received, 1)
+	assert.Same(s.T(), current, s.received[0])
+	assert.Equal(s.T(), reloads{true}, s.reloads)
+}
+
+func (s *WatcherTestSuite) TestReload_RejectsNonReloadableFields() {
+	// Arrange
+	s.write(`
+env: dev
+logging:
+  level: info
+grpc:
+  port: 9000
+  timeout: 5s
+limits:
+  max_notes: 30
+`)
+
+	// Act
+	err := s.watcher.Reload()
+
+	// Assert
+	require.NoError(s.T(), err)
+	current := s.watcher.Current()
+	assert.Equal(s.T(), 8080, *current.GRPC.Port)
+	assert.Equal(s.T(), 30, *current.Limits.MaxNotes)
+	// Удаление флагов тоже применяется
+	assert.Nil(s.T(), current.Features)
+}
+
+func (s *WatcherTestSuite) TestReload_KeepsConfigOnInvalidFile() {
+	// Arrange
+	initial := s.watcher.Current()
+	s.write(`
+env: dev
+grpc:
+  port: 8080
+  timeout: 5s
+limits:
+  max_note_size: -1
+`)
+
+	// Act
+	err := s.watcher.Reload()
+
+	// Assert
+	assert.Error(s.T(), err)
+	assert.Same(s.T(), initial, s.watcher.Current())
+	assert.Empty(s.T(), s.received)
+	assert.Equal(s.T(), reloads{false}, s.reloads)
+}
+
+func (s *WatcherTestSuite) TestReload_WithoutChanges() {
+	// Act
+	err := s.watcher.Reload()
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), s.received)
+	assert.Equal(s.T(), reloads{true}, s.reloads)
+}
+
+func (s *WatcherTestSuite) TestFileChanged() {
+	// Arrange
+	later := time.Now().Add(time.Minute)
+
+	// Act & Assert
+	assert.Empty(s.T(), s.watcher.fileChanged())
+	require.NoError(s.T(), os.Chtimes(s.path, later, later))
+	assert.Equal(s.T(), s.path, s.watcher.fileChanged())
+	assert.Empty(s.T(), s.watcher.fileChanged())
+}
@@ -0,0 +1,138 @@
+// Package deadline ограничивает время обработки gRPC вызовов
+package deadline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"ms_template/internal/config"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Источник дедлайна в метриках
+const (
+	SourceClient = "client" // действует дедлайн, переданный клиентом
+	SourceServer = "server" // дедлайн назначен или сокращен сервером
+)
+
+// Reporter учитывает вызовы, прерванные по дедлайну
+type Reporter interface {
+	DeadlineExceeded(ctx context.Context, method, source string)
+}
+
+// Interceptor назначает дедлайн вызовам без него и сокращает слишком
+// поздние дедлайны клиентов до предела из конфигурации
+type Interceptor struct {
+	reporter Reporter
+	rules    atomic.Pointer[rules]
+}
+
+// rules - пределы времени обработки, заменяются целиком через Update
+type rules struct {
+	fallback time.Duration
+	methods  map[string]time.Duration
+}
+
+func NewInterceptor(reporter Reporter, cfg config.GRPCConfig) *Interceptor {
+	i := &Interceptor{reporter: reporter}
+	i.Update(cfg)
+
+	return i
+}
+
+// Update применяет grpc.timeout и grpc.method_timeouts из новой
+// конфигурации. Действует для вызовов, начатых после обновления.
+func (i *Interceptor) Update(cfg config.GRPCConfig) {
+	r := &rules{methods: make(map[string]time.Duration, len(cfg.MethodTimeouts))}
+	if cfg.Timeout != nil {
+		r.fallback = *cfg.Timeout
+	}
+	for method, timeout := range cfg.MethodTimeouts {
+		r.methods[method] = timeout
+	}
+	i.rules.Store(r)
+}
+
+// UnaryServerInterceptor ограничивает время обработки unary вызова
+// значением grpc.method_timeouts или grpc.timeout
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		r := i.rules.Load()
+		limit, ok := r.methods[info.FullMethod]
+		if !ok {
+			limit = r.fallback
+		}
+
+		ctx, source, cancel := withLimit(ctx, limit)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if err = i.check(ctx, info.FullMethod, source, err); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor ограничивает время стрима только значением
+// grpc.method_timeouts: стримы вроде Health/Watch живут долго,
+// и общий grpc.timeout к ним не применяется
+func (i *Interceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		limit, ok := i.rules.Load().methods[info.FullMethod]
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		ctx, source, cancel := withLimit(ss.Context(), limit)
+		defer cancel()
+
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return i.check(ctx, info.FullMethod, source, handler(srv, wrapped))
+	}
+}
+
+// withLimit назначает дедлайн через limit, если у ctx нет дедлайна или
+// он наступает позже. Возвращает источник действующего дедлайна.
+func withLimit(ctx context.Context, limit time.Duration) (context.Context, string, context.CancelFunc) {
+	if limit <= 0 {
+		return ctx, SourceClient, func() {}
+	}
+	if d, ok := ctx.Deadline(); ok && time.Until(d) <= limit {
+		return ctx, SourceClient, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, limit)
+	return ctx, SourceServer, cancel
+}
+
+// check заменяет ошибку обработчика после истекшего дедлайна на
+// DeadlineExceeded и учитывает такой вызов: ошибка в этом случае обычно
+// следствие отмены контекста. Успешный ответ возвращается без изменений.
+func (i *Interceptor) check(ctx context.Context, method, source string, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	i.reporter.DeadlineExceeded(ctx, method, source)
+	if status.Code(err) == codes.DeadlineExceeded {
+		return err
+	}
+	return status.Error(codes.DeadlineExceeded, "превышено время обработки запроса")
+}
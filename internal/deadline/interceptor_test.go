@@ -0,0 +1,203 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ms_template/internal/config"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	addNote = "/notes.Notes/AddNote"
+	getNote = "/notes.Notes/GetNotes"
+)
+
+type reported struct {
+	method string
+	source string
+}
+
+type fakeReporter struct {
+	calls []reported
+}
+
+func (r *fakeReporter) DeadlineExceeded(_ context.Context, method, source string) {
+	r.calls = append(r.calls, reported{method: method, source: source})
+}
+
+type DeadlineTestSuite struct {
+	suite.Suite
+	reporter    *fakeReporter
+	interceptor *Interceptor
+}
+
+func TestDeadlineTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadlineTestSuite))
+}
+
+func (s *DeadlineTestSuite) SetupTest() {
+	timeout := time.Minute
+	s.reporter = &fakeReporter{}
+	s.interceptor = NewInterceptor(s.reporter, config.GRPCConfig{
+		Timeout:        &timeout,
+		MethodTimeouts: map[string]time.Duration{addNote: time.Second},
+	})
+}
+
+// call выполняет unary вызов и возвращает оставшееся до дедлайна время,
+// которое видел обработчик
+func (s *DeadlineTestSuite) call(ctx context.Context, method string, handler grpc.UnaryHandler) (time.Duration, error) {
+	var left time.Duration
+	_, err := s.interceptor.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req any) (any, error) {
+			d, ok := ctx.Deadline()
+			require.True(s.T(), ok, "обработчик должен получать контекст с дедлайном")
+			left = time.Until(d)
+			return handler(ctx, req)
+		})
+	return left, err
+}
+
+func ok(context.Context, any) (any, error) {
+	return "ok", nil
+}
+
+func (s *DeadlineTestSuite) TestUnary_DefaultTimeout() {
+	// Act
+	left, err := s.call(context.Background(), getNote, ok)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.InDelta(s.T(), time.Minute, left, float64(time.Second))
+}
+
+func (s *DeadlineTestSuite) TestUnary_MethodTimeout() {
+	// Act
+	left, err := s.call(context.Background(), addNote, ok)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.LessOrEqual(s.T(), left, time.Second)
+}
+
+func (s *DeadlineTestSuite) TestUnary_CapsClientDeadline() {
+	// Arrange
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// Act
+	left, err := s.call(ctx, addNote, ok)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.LessOrEqual(s.T(), left, time.Second)
+}
+
+func (s *DeadlineTestSuite) TestUnary_KeepsShorterClientDeadline() {
+	// Arrange
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Act
+	left, err := s.call(ctx, getNote, ok)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.LessOrEqual(s.T(), left, 100*time.Millisecond)
+}
+
+func (s *DeadlineTestSuite) TestUnary_ServerDeadlineExceeded() {
+	// Arrange
+	timeout := 10 * time.Millisecond
+	s.interceptor.Update(config.GRPCConfig{Timeout: &timeout})
+
+	// Act
+	_, err := s.call(context.Background(), getNote, func(ctx context.Context, _ any) (any, error) {
+		<-ctx.Done()
+		return nil, errors.New("хранилище недоступно")
+	})
+
+	// Assert
+	assert.Equal(s.T(), codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(s.T(), []reported{{method: getNote, source: SourceServer}}, s.reporter.calls)
+}
+
+func (s *DeadlineTestSuite) TestUnary_ClientDeadlineExceeded() {
+	// Arrange
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := s.call(ctx, getNote, func(ctx context.Context, _ any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// Assert
+	assert.Equal(s.T(), codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(s.T(), []reported{{method: getNote, source: SourceClient}}, s.reporter.calls)
+}
+
+func (s *DeadlineTestSuite) TestUnary_KeepsResponseAfterDeadline() {
+	// Arrange
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	resp, err := s.interceptor.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: getNote},
+		func(ctx context.Context, _ any) (any, error) {
+			<-ctx.Done()
+			return "поздно", nil
+		})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "поздно", resp)
+	assert.Empty(s.T(), s.reporter.calls)
+}
+
+func (s *DeadlineTestSuite) TestUnary_CanceledNotReported() {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err := s.call(ctx, getNote, func(ctx context.Context, _ any) (any, error) {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	})
+
+	// Assert
+	assert.Equal(s.T(), codes.Canceled, status.Code(err))
+	assert.Empty(s.T(), s.reporter.calls)
+}
+
+func (s *DeadlineTestSuite) TestStream_OnlyMethodTimeouts() {
+	// Arrange
+	stream := &middleware.WrappedServerStream{WrappedContext: context.Background()}
+	var withDeadline []bool
+	handler := func(_ any, ss grpc.ServerStream) error {
+		_, ok := ss.Context().Deadline()
+		withDeadline = append(withDeadline, ok)
+		return nil
+	}
+	intercept := s.interceptor.StreamServerInterceptor()
+
+	// Act
+	errWatch := intercept(nil, stream, &grpc.StreamServerInfo{FullMethod: "/grpc.health.v1.Health/Watch"}, handler)
+	errAdd := intercept(nil, stream, &grpc.StreamServerInfo{FullMethod: addNote}, handler)
+
+	// Assert
+	require.NoError(s.T(), errWatch)
+	require.NoError(s.T(), errAdd)
+	assert.Equal(s.T(), []bool{false, true}, withDeadline)
+}
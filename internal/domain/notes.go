@@ -1,11 +1,19 @@
 package domain
 
-import "time"
+import (
+	"time"
+)
 
 type Note struct {
 	ID        string
 	Title     string
 	Content   string
 	UserID    string
+	TenantID  string
 	CreatedAt time.Time
 }
+
+// Size возвращает размер заметки в байтах
+func (n Note) Size() int {
+	return len(n.Title) + len(n.Content)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Usage - текущее потребление хранилища
+type Usage struct {
+	Notes int64
+	Bytes int64
+}
+
+// Quota - ограничения хранилища, 0 означает отсутствие ограничения
+type Quota struct {
+	MaxNotes    int64
+	MaxBytes    int64
+	MaxNoteSize int64
+}
+
+// UsageReport - потребление и квоты пользователя и его tenant
+type UsageReport struct {
+	User        Usage
+	UserQuota   Quota
+	Tenant      Usage
+	TenantQuota Quota
+	// TenantIncluded - заполнены ли Tenant и TenantQuota: данные всего
+	// tenant выдаются только при включенном флаге tenant_usage
+	TenantIncluded bool
+}
+
+// QuotaViolation описывает одно нарушение квоты
+type QuotaViolation struct {
+	Subject     string // например "user:42" или "tenant:acme"
+	Description string
+}
+
+// QuotaError возвращается при превышении квоты хранения
+type QuotaError struct {
+	Violations []QuotaViolation
+}
+
+func (e *QuotaError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s: %s", v.Subject, v.Description))
+	}
+	return "превышена квота: " + strings.Join(parts, "; ")
+}
@@ -2,65 +2,65 @@ package notesGRPC
 
 import (
 	"context"
+	"errors"
 	"ms_template/gen/go/notes"
 	"ms_template/internal/domain"
+	"ms_template/internal/tenant"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-
-
-
-
-
-
 type ServerApi struct {
 	notes.UnimplementedNotesServer
 	noteServer NoteServer
 }
 
 type NoteServer interface {
-	AddNote(ctx context.Context, note domain.Note) string
-	GetNotes(ctx context.Context, userID string) []domain.Note
+	AddNote(ctx context.Context, note domain.Note) (string, error)
+	GetNotes(ctx context.Context, userID string) ([]domain.Note, error)
+	GetUsage(ctx context.Context, userID string) (domain.UsageReport, error)
 }
 
-
-
-
 func Register(grpcServer *grpc.Server, nt NoteServer) {
 	notes.RegisterNotesServer(grpcServer, &ServerApi{noteServer: nt})
 }
 
-
-func (s *ServerApi) AddNote(ctx context.Context, in *notes.AddNoteRequest) (*notes.AddNoteResponse, error){
+func (s *ServerApi) AddNote(ctx context.Context, in *notes.AddNoteRequest) (*notes.AddNoteResponse, error) {
 	note := domain.Note{
-		UserID: in.UserID,
-		Title: in.Note.Title,
+		UserID:  in.UserID,
+		Title:   in.Note.Title,
 		Content: in.Note.Content,
 	}
 
-	id := s.noteServer.AddNote(ctx, note)
+	id, err := s.noteServer.AddNote(ctx, note)
+	if err != nil {
+		return nil, toStatus(err)
+	}
 
 	out := notes.AddNoteResponse{
-		Id: id,
-		Title: note.Title,
+		Id:      id,
+		Title:   note.Title,
 		Content: note.Content,
 	}
 	return &out, nil
 }
 
+func (s *ServerApi) GetNotes(ctx context.Context, in *notes.GetNotesRequest) (*notes.GetNotesResponse, error) {
 
-func (s *ServerApi) GetNotes(ctx context.Context, in *notes.GetNotesRequest) (*notes.GetNotesResponse, error){
-	
-	
-	noteArr := s.noteServer.GetNotes(ctx, in.UserID)
+	noteArr, err := s.noteServer.GetNotes(ctx, in.UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
 
 	result := make([]*notes.Note, len(noteArr))
 
-	for i,v := range noteArr {
+	for i, v := range noteArr {
 		result[i] = &notes.Note{
-			Id: v.ID,
-			Title: v.Title,
+			Id:      v.ID,
+			Title:   v.Title,
 			Content: v.Content,
 		}
 	}
@@ -72,3 +72,62 @@ func (s *ServerApi) GetNotes(ctx context.Context, in *notes.GetNotesRequest) (*n
 	return &out, nil
 }
 
+func (s *ServerApi) GetUsage(ctx context.Context, in *notes.GetUsageRequest) (*notes.GetUsageResponse, error) {
+
+	report, err := s.noteServer.GetUsage(ctx, in.UserID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	out := notes.GetUsageResponse{
+		User: toUsage(report.User, report.UserQuota),
+	}
+	if report.TenantIncluded {
+		out.Tenant = toUsage(report.Tenant, report.TenantQuota)
+	}
+
+	return &out, nil
+}
+
+func toUsage(u domain.Usage, q domain.Quota) *notes.Usage {
+	return &notes.Usage{
+		NotesCount: u.Notes,
+		TotalBytes: u.Bytes,
+		Quota: &notes.Quota{
+			MaxNotes:    q.MaxNotes,
+			MaxBytes:    q.MaxBytes,
+			MaxNoteSize: q.MaxNoteSize,
+		},
+	}
+}
+
+// toStatus преобразует ошибки бизнес-логики в gRPC статусы
+func toStatus(err error) error {
+	var quotaErr *domain.QuotaError
+
+	switch {
+	case errors.Is(err, tenant.ErrMissing):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.As(err, &quotaErr):
+		return quotaStatus(quotaErr)
+	default:
+		return status.Error(codes.Internal, "внутренняя ошибка сервера")
+	}
+}
+
+// quotaStatus возвращает ResourceExhausted с деталями errdetails.QuotaFailure
+func quotaStatus(err *domain.QuotaError) error {
+	failure := &errdetails.QuotaFailure{}
+	for _, v := range err.Violations {
+		failure.Violations = append(failure.Violations, &errdetails.QuotaFailure_Violation{
+			Subject:     v.Subject,
+			Description: v.Description,
+		})
+	}
+
+	st, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(failure)
+	if detailsErr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
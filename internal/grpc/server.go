@@ -1,83 +1,333 @@
 package grpcserver
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	adminpb "ms_template/gen/go/admin"
+	"ms_template/gen/go/notes"
+	"ms_template/internal/auth"
+	"ms_template/internal/certs"
+	"ms_template/internal/config"
+	"ms_template/internal/deadline"
 	"ms_template/internal/grpc/notesGRPC"
+	"ms_template/internal/health"
+	"ms_template/internal/logger"
 	metrics "ms_template/internal/metric"
+	"ms_template/internal/ratelimit"
+	"ms_template/internal/requestid"
+	"ms_template/internal/tenant"
 	"net"
+	"strings"
+	"sync/atomic"
 
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/selector"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 )
 
 type App struct {
-    log        *slog.Logger
-    gRPCServer *grpc.Server
-    metrics      *metrics.Metrics // Добавляем метрики
-    port         int              // gRPC порт
-    metricsPort  int
+	log        *slog.Logger
+	gRPCServer *grpc.Server
+	listener   net.Listener
+	metrics    *metrics.Metrics       // Добавляем метрики
+	certs      *certs.Reloader        // nil в режиме insecure
+	redis      *redis.Client          // nil, если лимитер не использует Redis
+	limits     *ratelimit.Interceptor // nil, если rate_limit.enabled выключен
+	deadlines  *deadline.Interceptor
+	// redisPassword - актуальный пароль Redis для новых соединений
+	redisPassword *atomic.Value
+	watchCtx      context.Context
+	stopWatch     context.CancelFunc
+	port          int // gRPC порт
+	metricsPort   int
+	tlsMode       string
 }
 
+func New(log *slog.Logger, NoteServer notesGRPC.NoteServer, cfg *config.Config, checks *health.Registry, metrics *metrics.Metrics) (*App, error) {
+	tenants := tenant.NewResolver(cfg.Tenancy.Header, cfg.Tenancy.Default, cfg.Tenancy.Required, cfg.Tenancy.TrustedProxies)
 
-func New(log *slog.Logger, NoteServer notesGRPC.NoteServer, port int, metricsPort int) *App {
-	metrics := metrics.New("notes_service")
-    
-    // Настраиваем gRPC сервер с interceptors для метрик
-    gRPCServer := grpc.NewServer(
-        grpc.ChainUnaryInterceptor(
-            recovery.UnaryServerInterceptor(),
-            metrics.UnaryServerInterceptor(), // Добавляем метрики interceptor
-        ),
-        grpc.ChainStreamInterceptor(
-            metrics.StreamServerInterceptor(), // Для stream соединений
-        ),
-    )
+	creds, reloader, err := transportCredentials(log, cfg.GRPC.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	// request id идет первым, чтобы идентификатор попадал и в ошибки от recovery.
+	// Метрики стоят после auth, но перед tenant, чтобы учитывать вызовы,
+	// отклоненные при определении tenant; метку tenant они вычисляют сами.
+	// Дедлайн назначается после метрик и журнала вызовов, чтобы они видели
+	// код DeadlineExceeded, который получит клиент.
+	tenantOf := func(ctx context.Context) string {
+		if method, ok := grpc.Method(ctx); ok && isInfrastructure(method) {
+			return ""
+		}
+		id, _ := tenants.Resolve(ctx)
+		return id
+	}
+	deadlines := deadline.NewInterceptor(metrics, cfg.GRPC)
+	unary := []grpc.UnaryServerInterceptor{
+		requestid.UnaryServerInterceptor(),
+		recovery.UnaryServerInterceptor(),
+		auth.UnaryServerInterceptor(),
+		metrics.UnaryServerInterceptor(tenantOf), // Добавляем метрики interceptor
+		selector.UnaryServerInterceptor(tenants.UnaryServerInterceptor(), notInfrastructure),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		requestid.StreamServerInterceptor(),
+		auth.StreamServerInterceptor(),
+		metrics.StreamServerInterceptor(tenantOf), // Для stream соединений
+		selector.StreamServerInterceptor(tenants.StreamServerInterceptor(), notInfrastructure),
+	}
+
+	// Журнал вызовов после auth и tenant, чтобы записи содержали пользователя
+	// и tenant, и перед дедлайном и лимитером, чтобы код в записи совпадал
+	// с ответом клиенту, а отклоненные вызовы тоже записывались.
+	// Пробы health в журнал не попадают.
+	if cfg.AccessLog.Enabled {
+		accessLog := logger.NewAccessLog(logger.Unsampled(logger.Named(log, "access")), cfg.AccessLog)
+		unary = append(unary, selector.UnaryServerInterceptor(accessLog.UnaryServerInterceptor(), notHealthCheck))
+		stream = append(stream, selector.StreamServerInterceptor(accessLog.StreamServerInterceptor(), notHealthCheck))
+	}
+	unary = append(unary, deadlines.UnaryServerInterceptor())
+	stream = append(stream, deadlines.StreamServerInterceptor())
+
+	// Лимитер стоит после метрик, чтобы отклоненные вызовы тоже учитывались
+	var redisClient *redis.Client
+	redisPassword := new(atomic.Value)
+	var limits *ratelimit.Interceptor
+	if cfg.RateLimit.Enabled {
+		var limiter ratelimit.Limiter = ratelimit.NewLocal()
+		if cfg.RateLimit.Backend == config.RateLimitBackendRedis {
+			// Пароль читается при каждом новом соединении, чтобы ротация
+			// секрета применялась без перезапуска
+			redisPassword.Store(cfg.RateLimit.Redis.Password.Value())
+			redisClient = redis.NewClient(&redis.Options{
+				Addr: cfg.RateLimit.Redis.Addr,
+				CredentialsProvider: func() (string, string) {
+					return "", redisPassword.Load().(string)
+				},
+				DB: cfg.RateLimit.Redis.DB,
+			})
+			limiter = ratelimit.NewRedis(log, redisClient, limiter, cfg.RateLimit.Redis.Prefix, cfg.RateLimit.Redis.Timeout, cfg.RateLimit.Redis.Backoff)
+			// При недоступном Redis работает локальный лимит, поэтому проверка необязательная
+			checks.RegisterOptional("redis", health.CheckerFunc(func(ctx context.Context) error {
+				return redisClient.Ping(ctx).Err()
+			}))
+		}
+
+		limits = ratelimit.NewInterceptor(logger.Named(log, "ratelimit"), limiter, metrics, cfg.RateLimit)
+		unary = append(unary, selector.UnaryServerInterceptor(limits.UnaryServerInterceptor(), notInfrastructure))
+		stream = append(stream, selector.StreamServerInterceptor(limits.StreamServerInterceptor(), notInfrastructure))
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.Creds(creds),
+		grpc.StatsHandler(metrics.StatsHandler()), // Соединения, байты и размеры сообщений
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	opts = append(opts, transportOptions(cfg.GRPC)...)
+	if cfg.Tracing.Enabled {
+		// Server span на каждый вызов, кроме проб health. Провайдер и propagator
+		// берутся глобальные, их устанавливает tracing.New.
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithFilter(filters.Not(filters.HealthCheck())),
+		)))
+	}
+
+	// Настраиваем gRPC сервер с interceptors для метрик
+	gRPCServer := grpc.NewServer(opts...)
 
 	notesGRPC.Register(gRPCServer, NoteServer)
-	
+	healthpb.RegisterHealthServer(gRPCServer, checks.Server())
+	checks.AddService(notes.Notes_ServiceDesc.ServiceName)
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+
 	return &App{
-        log:         log,
-        gRPCServer:  gRPCServer,
-        metrics:     metrics,
-        port:        port,
-        metricsPort: metricsPort,
-    }
+		log:           log,
+		gRPCServer:    gRPCServer,
+		metrics:       metrics,
+		certs:         reloader,
+		redis:         redisClient,
+		limits:        limits,
+		deadlines:     deadlines,
+		redisPassword: redisPassword,
+		watchCtx:      watchCtx,
+		stopWatch:     stopWatch,
+		port:          *cfg.GRPC.Port,
+		metricsPort:   *cfg.Prometheus.Port,
+		tlsMode:       cfg.GRPC.TLS.Mode,
+	}, nil
 }
 
+// UpdateConfig применяет перезагружаемые настройки сервера: дедлайны
+// вызовов, правила ограничения частоты и пароль Redis
+func (a *App) UpdateConfig(cfg *config.Config) {
+	a.deadlines.Update(cfg.GRPC)
+	if a.limits != nil {
+		a.limits.Update(cfg.RateLimit)
+	}
+	a.redisPassword.Store(cfg.RateLimit.Redis.Password.Value())
+}
 
-func (a *App) Run() error {
+// notHealthCheck исключает вызовы health-сервиса из журнала вызовов
+var notHealthCheck = selector.MatchFunc(func(_ context.Context, c interceptors.CallMeta) bool {
+	return c.Service != healthpb.Health_ServiceDesc.ServiceName
+})
+
+// notInfrastructure исключает служебные сервисы из tenant и лимитов:
+// пробы оркестратора и администраторы не передают tenant и не должны отклоняться
+var notInfrastructure = selector.MatchFunc(func(_ context.Context, c interceptors.CallMeta) bool {
+	return !isInfrastructure(c.FullMethod())
+})
+
+// isInfrastructure проверяет, относится ли полное имя метода к health или admin
+func isInfrastructure(fullMethod string) bool {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service == healthpb.Health_ServiceDesc.ServiceName || service == adminpb.Admin_ServiceDesc.ServiceName
+}
 
-    l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
-    if err != nil {
-        return fmt.Errorf("ошибка прослушивания порта %d: %w", a.port, err)
-    }
+// transportCredentials строит учетные данные транспорта по режиму TLS
+func transportCredentials(log *slog.Logger, cfg config.TLSConfig) (credentials.TransportCredentials, *certs.Reloader, error) {
+	if cfg.Mode == config.TLSModeInsecure {
+		return insecure.NewCredentials(), nil, nil
+	}
 
-    a.log.Info("gRPC server started", 
-        slog.String("addr", l.Addr().String()),
-        slog.Int("metrics_port", a.metricsPort),
-    )
+	clientCAFile := ""
+	if cfg.Mode == config.TLSModeMTLS {
+		clientCAFile = cfg.ClientCAFile
+	}
 
-    // Запускаем обработчик gRPC-сообщений
-    if err := a.gRPCServer.Serve(l); err != nil {
-        return fmt.Errorf("ошибка обслуживания grpc сервера: %w", err)
-    }
+	reloader, err := certs.NewReloader(logger.Named(log, "certs"), cfg.CertFile, cfg.KeyFile, clientCAFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка загрузки сертификатов gRPC: %w", err)
+	}
 
-    return nil
+	tlsConfig := reloader.ServerConfig(cfg.Mode == config.TLSModeMTLS)
+	return credentials.NewTLS(tlsConfig), reloader, nil
 }
 
+// transportOptions переносит настройки keepalive, потоков, размеров
+// сообщений и окон HTTP/2 из конфигурации в параметры сервера
+func transportOptions(cfg config.GRPCConfig) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.Keepalive.MaxConnectionIdle,
+			MaxConnectionAge:      cfg.Keepalive.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.Keepalive.MaxConnectionAgeGrace,
+			Time:                  cfg.Keepalive.Time,
+			Timeout:               cfg.Keepalive.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.Keepalive.Enforcement.MinTime,
+			PermitWithoutStream: cfg.Keepalive.Enforcement.PermitWithoutStream,
+		}),
+		grpc.MaxConcurrentStreams(uint32(cfg.MaxConcurrentStreams)),
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.MaxSendMsgSize),
+		grpc.ConnectionTimeout(cfg.ConnectionTimeout),
+	}
+	// Без явных окон gRPC подбирает их по BDP канала
+	if cfg.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(int32(cfg.InitialWindowSize)))
+	}
+	if cfg.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(int32(cfg.InitialConnWindowSize)))
+	}
+	return opts
+}
 
+func (a *App) Run() error {
+	if err := a.Listen(); err != nil {
+		return err
+	}
+	return a.Serve()
+}
+
+// Listen привязывает листенер к порту, не начиная обслуживание.
+// Позволяет обнаружить занятый порт до того, как приложение объявит готовность.
+func (a *App) Listen() error {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		return fmt.Errorf("ошибка прослушивания порта %d: %w", a.port, err)
+	}
+	a.listener = l
+
+	return nil
+}
+
+// Serve обслуживает соединения на листенере из Listen до остановки сервера
+func (a *App) Serve() error {
+	if a.certs != nil {
+		go a.certs.Watch(a.watchCtx)
+	}
+
+	a.log.Info("gRPC server started",
+		slog.String("addr", a.listener.Addr().String()),
+		slog.String("tls_mode", a.tlsMode),
+		slog.Int("metrics_port", a.metricsPort),
+	)
+
+	// Запускаем обработчик gRPC-сообщений
+	if err := a.gRPCServer.Serve(a.listener); err != nil {
+		return fmt.Errorf("ошибка обслуживания grpc сервера: %w", err)
+	}
+
+	return nil
+}
+
+// RegisterService регистрирует дополнительный gRPC сервис, например
+// административный. Вызывается до Serve.
+func (a *App) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	a.gRPCServer.RegisterService(desc, impl)
+}
 
 // MustRun запускает приложение и паникует при ошибке
 func (a *App) MustRun() {
-    if err := a.Run(); err != nil {
-        panic(err)
-    }
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
 }
 
 // GracefulStop останавливает сервер
 func (a *App) GracefulStop() {
-    a.log.Info("Shutting down gRPC server...")
-    a.gRPCServer.GracefulStop()
-    a.log.Info("gRPC server stopped")
-}
\ No newline at end of file
+	a.log.Info("Shutting down gRPC server...")
+	a.gRPCServer.GracefulStop()
+	a.closeListener()
+	a.stopWatch()
+	if a.redis != nil {
+		if err := a.redis.Close(); err != nil {
+			a.log.Warn("Ошибка закрытия соединения с Redis", "error", err)
+		}
+	}
+	a.log.Info("gRPC server stopped")
+}
+
+// Stop немедленно закрывает все соединения и прерывает текущие вызовы
+func (a *App) Stop() {
+	a.gRPCServer.Stop()
+	a.closeListener()
+	a.stopWatch()
+}
+
+// closeListener закрывает листенер из Listen. gRPC закрывает только
+// листенеры, переданные в Serve, поэтому без этого листенер остается
+// открытым, если запуск приложения прервался до Serve.
+func (a *App) closeListener() {
+	if a.listener == nil {
+		return
+	}
+	if err := a.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		a.log.Warn("Ошибка закрытия листенера gRPC", "error", err)
+	}
+}
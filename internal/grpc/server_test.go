@@ -0,0 +1,154 @@
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ms_template/gen/go/notes"
+	"ms_template/internal/config"
+	"ms_template/internal/domain"
+	"ms_template/internal/health"
+	metrics "ms_template/internal/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// slowNotes отвечает только после отмены контекста вызова
+type slowNotes struct{}
+
+func (slowNotes) AddNote(ctx context.Context, _ domain.Note) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (slowNotes) GetNotes(context.Context, string) ([]domain.Note, error) {
+	return nil, nil
+}
+
+func (slowNotes) GetUsage(context.Context, string) (domain.UsageReport, error) {
+	return domain.UsageReport{}, nil
+}
+
+// syncBuffer - буфер для логов, которые пишут горутины сервера
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+	logs *syncBuffer
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (s *ServerTestSuite) SetupTest() {
+	s.logs = &syncBuffer{}
+}
+
+// start запускает сервер с конфигурацией из YAML, дополненной портом,
+// и возвращает клиент сервиса заметок
+func (s *ServerTestSuite) start(body string) notes.NotesClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(s.T(), err)
+	port := lis.Addr().(*net.TCPAddr).Port
+	require.NoError(s.T(), lis.Close())
+
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	yaml := fmt.Sprintf("grpc:\n  port: %d\n  tls:\n    mode: insecure\n", port) + body
+	require.NoError(s.T(), os.WriteFile(path, []byte(yaml), 0o600))
+	loaded, err := config.Load(config.LoadOptions{Path: path})
+	require.NoError(s.T(), err)
+
+	log := slog.New(slog.NewJSONHandler(s.logs, nil))
+	checks := health.NewRegistry(log, time.Minute, time.Second)
+	app, err := New(log, slowNotes{}, loaded.Config, checks, metrics.New("test", metrics.Options{}))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), app.Listen())
+	go app.Serve()
+	s.T().Cleanup(app.Stop)
+
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(s.T(), err)
+	s.T().Cleanup(func() { conn.Close() })
+	return notes.NewNotesClient(conn)
+}
+
+// accessLog возвращает записи журнала вызовов
+func (s *ServerTestSuite) accessLog() []map[string]any {
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(s.logs.String()), "\n") {
+		var entry map[string]any
+		if json.Unmarshal([]byte(line), &entry) == nil && entry["logger"] == "access" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (s *ServerTestSuite) TestAccessLog_RecordsDeadlineExceeded() {
+	// Arrange
+	client := s.start(`  timeout: 50ms
+access_log:
+  enabled: true
+`)
+
+	// Act
+	_, err := client.AddNote(context.Background(), &notes.AddNoteRequest{
+		UserID: "user-1",
+		Note:   &notes.Note{Title: "title", Content: "content"},
+	})
+
+	// Assert
+	assert.Equal(s.T(), codes.DeadlineExceeded, status.Code(err))
+	require.Eventually(s.T(), func() bool { return len(s.accessLog()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(s.T(), codes.DeadlineExceeded.String(), s.accessLog()[0]["code"])
+}
+
+func (s *ServerTestSuite) TestTransport_MaxRecvMsgSize() {
+	// Arrange
+	client := s.start(`  timeout: 1s
+  max_recv_msg_size: 1024
+`)
+
+	// Act
+	_, small := client.GetNotes(context.Background(), &notes.GetNotesRequest{UserID: "user-1"})
+	_, large := client.AddNote(context.Background(), &notes.AddNoteRequest{
+		UserID: "user-1",
+		Note:   &notes.Note{Title: "title", Content: strings.Repeat("x", 2048)},
+	})
+
+	// Assert
+	require.NoError(s.T(), small)
+	assert.Equal(s.T(), codes.ResourceExhausted, status.Code(large))
+}
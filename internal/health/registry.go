@@ -0,0 +1,271 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Статусы проверок в JSON отчете
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker проверяет доступность зависимости
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc позволяет использовать функцию как Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult - результат последней проверки зависимости
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Optional  bool      `json:"optional,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Duration  string    `json:"duration"`
+}
+
+// Report - агрегированное состояние сервиса
+type Report struct {
+	Status   string                 `json:"status"`
+	Services map[string]string      `json:"services"`
+	Checks   map[string]CheckResult `json:"checks"`
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	services []string
+	optional bool
+}
+
+// Registry периодически опрашивает зависимости и обновляет статусы
+// стандартного сервиса grpc.health.v1.Health. Сервис gRPC считается
+// SERVING, только если прошли все обязательные проверки, привязанные к нему.
+// Общий статус (пустое имя сервиса) учитывает все обязательные проверки.
+type Registry struct {
+	log      *slog.Logger
+	server   *grpchealth.Server
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.RWMutex
+	checks   []check
+	services map[string]struct{}
+	results  map[string]CheckResult
+	statuses map[string]healthpb.HealthCheckResponse_ServingStatus
+	// shutdown - сервис останавливается, все статусы NOT_SERVING
+	shutdown bool
+}
+
+func NewRegistry(log *slog.Logger, interval, timeout time.Duration) *Registry {
+	r := &Registry{
+		log:      log,
+		server:   grpchealth.NewServer(),
+		interval: interval,
+		timeout:  timeout,
+		services: map[string]struct{}{"": {}},
+		results:  make(map[string]CheckResult),
+		statuses: make(map[string]healthpb.HealthCheckResponse_ServingStatus),
+	}
+	// До первой проверки сервис не готов принимать трафик
+	r.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return r
+}
+
+// Server возвращает реализацию grpc.health.v1.Health для регистрации в gRPC
+func (r *Registry) Server() *grpchealth.Server {
+	return r.server
+}
+
+// Shutdown переводит все сервисы в NOT_SERVING до завершения процесса,
+// в том числе в HTTP отчете. Последующие проверки статусы не меняют.
+func (r *Registry) Shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.shutdown = true
+	r.server.Shutdown()
+}
+
+// AddService объявляет gRPC сервис, статус которого публикуется
+func (r *Registry) AddService(service string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.services[service] = struct{}{}
+	r.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Register добавляет обязательную проверку. Ее провал переводит
+// в NOT_SERVING перечисленные сервисы и общий статус.
+func (r *Registry) Register(name string, checker Checker, services ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, check{name: name, checker: checker, services: services})
+}
+
+// RegisterOptional добавляет проверку, которая попадает в отчет,
+// но не влияет на статус, например зависимость с локальным fallback
+func (r *Registry) RegisterOptional(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, check{name: name, checker: checker, optional: true})
+}
+
+// Run выполняет проверки сразу и далее каждые interval до отмены контекста
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.CheckNow(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckNow выполняет все проверки и обновляет статусы
+func (r *Registry) CheckNow(ctx context.Context) {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make(map[string]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := r.run(ctx, c)
+			resultsMu.Lock()
+			results[c.name] = res
+			resultsMu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, res := range results {
+		prev, ok := r.results[name]
+		if res.Status == StatusDown && (!ok || prev.Status != StatusDown) {
+			r.log.Warn("Проверка зависимости не прошла", "check", name, "error", res.Error)
+		}
+		if res.Status == StatusUp && ok && prev.Status == StatusDown {
+			r.log.Info("Зависимость восстановлена", "check", name)
+		}
+	}
+	r.results = results
+
+	for service := range r.services {
+		st := healthpb.HealthCheckResponse_SERVING
+		for _, c := range checks {
+			if c.optional || results[c.name].Status == StatusUp {
+				continue
+			}
+			if service == "" || slices.Contains(c.services, service) {
+				st = healthpb.HealthCheckResponse_NOT_SERVING
+				break
+			}
+		}
+		r.statuses[service] = st
+		r.server.SetServingStatus(service, st)
+	}
+}
+
+func (r *Registry) run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+
+	res := CheckResult{
+		Status:    StatusUp,
+		Optional:  c.optional,
+		CheckedAt: start,
+		Duration:  time.Since(start).String(),
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Serving сообщает, обслуживает ли сервис запросы по итогам последних проверок
+func (r *Registry) Serving() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return !r.shutdown && r.statuses[""] == healthpb.HealthCheckResponse_SERVING
+}
+
+// Report возвращает агрегированное состояние
+func (r *Registry) Report() Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := Report{
+		Status:   healthpb.HealthCheckResponse_NOT_SERVING.String(),
+		Services: make(map[string]string, len(r.services)),
+		Checks:   make(map[string]CheckResult, len(r.results)),
+	}
+
+	for service := range r.services {
+		st, ok := r.statuses[service]
+		if !ok || r.shutdown {
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if service == "" {
+			report.Status = st.String()
+			continue
+		}
+		report.Services[service] = st.String()
+	}
+	for name, res := range r.results {
+		report.Checks[name] = res
+	}
+
+	return report
+}
+
+// Handler отдает отчет в JSON: 200, если сервис обслуживает запросы, иначе 503
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Report()
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status == healthpb.HealthCheckResponse_SERVING.String() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
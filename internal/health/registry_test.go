@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type RegistryTestSuite struct {
+	suite.Suite
+	registry *Registry
+	repoErr  error
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func (s *RegistryTestSuite) SetupTest() {
+	s.repoErr = nil
+	s.registry = NewRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second, time.Second)
+	s.registry.AddService("notes.Notes")
+	s.registry.AddService("other.Service")
+	s.registry.Register("repository", CheckerFunc(func(context.Context) error { return s.repoErr }), "notes.Notes")
+	s.registry.RegisterOptional("cache", CheckerFunc(func(context.Context) error { return errors.New("cache down") }))
+}
+
+func (s *RegistryTestSuite) TestNotServingBeforeFirstCheck() {
+	// Act
+	resp, err := s.registry.Server().Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	assert.False(s.T(), s.registry.Serving())
+}
+
+func (s *RegistryTestSuite) TestCheckNow_AllRequiredUp() {
+	// Act
+	s.registry.CheckNow(context.Background())
+
+	// Assert: необязательная проверка не влияет на статус
+	assert.True(s.T(), s.registry.Serving())
+	s.assertStatus("notes.Notes", healthpb.HealthCheckResponse_SERVING)
+	s.assertStatus("other.Service", healthpb.HealthCheckResponse_SERVING)
+}
+
+func (s *RegistryTestSuite) TestCheckNow_RequiredDown() {
+	// Arrange
+	s.repoErr = errors.New("connection refused")
+
+	// Act
+	s.registry.CheckNow(context.Background())
+
+	// Assert: падает только сервис, зависящий от проверки, и общий статус
+	assert.False(s.T(), s.registry.Serving())
+	s.assertStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	s.assertStatus("notes.Notes", healthpb.HealthCheckResponse_NOT_SERVING)
+	s.assertStatus("other.Service", healthpb.HealthCheckResponse_SERVING)
+}
+
+func (s *RegistryTestSuite) TestHandler_ReportsDetails() {
+	// Arrange
+	s.repoErr = errors.New("connection refused")
+	s.registry.CheckNow(context.Background())
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	// Assert
+	assert.Equal(s.T(), http.StatusServiceUnavailable, rec.Code)
+
+	var report Report
+	require.NoError(s.T(), json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(s.T(), "NOT_SERVING", report.Status)
+	assert.Equal(s.T(), "NOT_SERVING", report.Services["notes.Notes"])
+	assert.Equal(s.T(), StatusDown, report.Checks["repository"].Status)
+	assert.Equal(s.T(), "connection refused", report.Checks["repository"].Error)
+	assert.True(s.T(), report.Checks["cache"].Optional)
+}
+
+func (s *RegistryTestSuite) TestShutdown_ReportsNotServing() {
+	// Arrange
+	s.registry.CheckNow(context.Background())
+	require.True(s.T(), s.registry.Serving())
+
+	// Act: проверка после остановки не возвращает SERVING
+	s.registry.Shutdown()
+	s.registry.CheckNow(context.Background())
+	report := s.registry.Report()
+
+	// Assert
+	assert.False(s.T(), s.registry.Serving())
+	assert.Equal(s.T(), "NOT_SERVING", report.Status)
+	assert.Equal(s.T(), "NOT_SERVING", report.Services["other.Service"])
+	s.assertStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func (s *RegistryTestSuite) assertStatus(service string, expected healthpb.HealthCheckResponse_ServingStatus) {
+	resp, err := s.registry.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), expected, resp.Status, "сервис %q", service)
+}
@@ -0,0 +1,202 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Component - часть приложения с управляемым жизненным циклом
+type Component struct {
+	Name string
+	// DependsOn - компоненты, которые должны быть запущены раньше
+	// и остановлены позже этого
+	DependsOn []string
+	// Start запускает компонент и возвращает управление после готовности,
+	// например после привязки листенера. Ошибка прерывает запуск приложения.
+	Start func(ctx context.Context) error
+	// Serve - необязательный блокирующий цикл обслуживания, запускается
+	// после успешного Start всех компонентов. Его завершение до остановки
+	// считается сбоем и останавливает приложение.
+	Serve func() error
+	// Stop останавливает компонент в пределах ctx
+	Stop func(ctx context.Context) error
+	// StopTimeout ограничивает Stop; 0 - таймаут менеджера по умолчанию
+	StopTimeout time.Duration
+}
+
+// Manager запускает компоненты в порядке зависимостей
+// и останавливает в обратном порядке
+type Manager struct {
+	log *slog.Logger
+	// stopTimeout ограничивает остановку приложения целиком
+	// и каждого компонента без собственного StopTimeout
+	stopTimeout time.Duration
+	components  []Component
+	started     []Component
+	failures    chan error
+}
+
+func New(log *slog.Logger, stopTimeout time.Duration) *Manager {
+	return &Manager{
+		log:         log,
+		stopTimeout: stopTimeout,
+	}
+}
+
+// Add регистрирует компонент
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+// Run запускает компоненты, ждет отмены ctx или сбоя одного из них
+// и останавливает все запущенные компоненты. Остановка ограничена
+// таймаутом из New и не зависит от уже отмененного ctx.
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+
+	runErr := m.Wait(ctx)
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), m.stopTimeout)
+	defer cancel()
+
+	return errors.Join(runErr, m.Stop(stopCtx))
+}
+
+// Start запускает компоненты в порядке зависимостей. При ошибке
+// уже запущенные компоненты останавливаются, а ошибка возвращается.
+func (m *Manager) Start(ctx context.Context) error {
+	order, err := m.order()
+	if err != nil {
+		return err
+	}
+
+	m.failures = make(chan error, len(order))
+
+	for _, c := range order {
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				startErr := fmt.Errorf("ошибка запуска компонента %s: %w", c.Name, err)
+
+				stopCtx, cancel := context.WithTimeout(context.Background(), m.stopTimeout)
+				stopErr := m.Stop(stopCtx)
+				cancel()
+
+				return errors.Join(startErr, stopErr)
+			}
+		}
+		m.started = append(m.started, c)
+		m.log.Info("Компонент запущен", "component", c.Name)
+	}
+
+	for _, c := range m.started {
+		if c.Serve == nil {
+			continue
+		}
+		go func() {
+			err := c.Serve()
+			if err == nil {
+				err = errors.New("обслуживание завершилось")
+			}
+			m.failures <- fmt.Errorf("компонент %s: %w", c.Name, err)
+		}()
+	}
+
+	return nil
+}
+
+// Wait блокируется до отмены ctx или сбоя компонента
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-m.failures:
+		m.log.Error("Сбой компонента, приложение останавливается", "error", err)
+		return err
+	}
+}
+
+// Stop останавливает запущенные компоненты в обратном порядке.
+// Каждый компонент получает собственный таймаут, но не больше остатка ctx.
+func (m *Manager) Stop(ctx context.Context) error {
+	var errs []error
+
+	for i := len(m.started) - 1; i >= 0; i-- {
+		c := m.started[i]
+		if c.Stop == nil {
+			continue
+		}
+
+		timeout := c.StopTimeout
+		if timeout == 0 {
+			timeout = m.stopTimeout
+		}
+		stopCtx, cancel := context.WithTimeout(ctx, timeout)
+
+		m.log.Info("Остановка компонента", "component", c.Name)
+		if err := c.Stop(stopCtx); err != nil {
+			errs = append(errs, fmt.Errorf("ошибка остановки компонента %s: %w", c.Name, err))
+		}
+		cancel()
+	}
+	m.started = nil
+
+	return errors.Join(errs...)
+}
+
+// order возвращает компоненты в порядке зависимостей (топологическая
+// сортировка), сохраняя порядок добавления для независимых компонентов
+func (m *Manager) order() ([]Component, error) {
+	byName := make(map[string]Component, len(m.components))
+	for _, c := range m.components {
+		if _, ok := byName[c.Name]; ok {
+			return nil, fmt.Errorf("компонент %s зарегистрирован дважды", c.Name)
+		}
+		byName[c.Name] = c
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(m.components))
+	order := make([]Component, 0, len(m.components))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		c, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("компонент %s зависит от незарегистрированного %s", path[len(path)-1], name)
+		}
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("циклическая зависимость компонентов: %v", append(path, name))
+		}
+
+		state[name] = visiting
+		for _, dep := range c.DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, c)
+		return nil
+	}
+
+	for _, c := range m.components {
+		if err := visit(c.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
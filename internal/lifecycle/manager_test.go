@@ -0,0 +1,173 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ManagerTestSuite struct {
+	suite.Suite
+	manager *Manager
+	mu      sync.Mutex
+	events  []string
+}
+
+func TestManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(ManagerTestSuite))
+}
+
+func (s *ManagerTestSuite) SetupTest() {
+	s.manager = New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second)
+	s.events = nil
+}
+
+func (s *ManagerTestSuite) record(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// component создает компонент, записывающий события запуска и остановки
+func (s *ManagerTestSuite) component(name string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(context.Context) error {
+			s.record("start " + name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			s.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (s *ManagerTestSuite) TestRun_DependencyOrder() {
+	// Arrange: компоненты добавлены не в порядке зависимостей
+	s.manager.Add(s.component("readiness", "grpc", "http"))
+	s.manager.Add(s.component("grpc", "health"))
+	s.manager.Add(s.component("http"))
+	s.manager.Add(s.component("health"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := s.manager.Run(ctx)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{
+		"start health", "start grpc", "start http", "start readiness",
+		"stop readiness", "stop http", "stop grpc", "stop health",
+	}, s.events)
+}
+
+func (s *ManagerTestSuite) TestStart_FailureStopsStarted() {
+	// Arrange
+	s.manager.Add(s.component("health"))
+	failing := s.component("grpc", "health")
+	failing.Start = func(context.Context) error { return errors.New("address already in use") }
+	s.manager.Add(failing)
+	s.manager.Add(s.component("readiness", "grpc"))
+
+	// Act
+	err := s.manager.Run(context.Background())
+
+	// Assert: запущенные компоненты остановлены, следующие не запускались
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "grpc")
+	assert.Contains(s.T(), err.Error(), "address already in use")
+	assert.Equal(s.T(), []string{"start health", "stop health"}, s.events)
+}
+
+func (s *ManagerTestSuite) TestRun_ServeFailureStopsApp() {
+	// Arrange
+	s.manager.Add(s.component("health"))
+	server := s.component("grpc", "health")
+	server.Serve = func() error { return errors.New("listener closed") }
+	s.manager.Add(server)
+
+	// Act: ctx не отменяется, Run должен завершиться из-за сбоя
+	done := make(chan error, 1)
+	go func() { done <- s.manager.Run(context.Background()) }()
+
+	// Assert
+	select {
+	case err := <-done:
+		require.Error(s.T(), err)
+		assert.Contains(s.T(), err.Error(), "listener closed")
+	case <-time.After(time.Second):
+		s.T().Fatal("Run не завершился после сбоя компонента")
+	}
+	assert.Equal(s.T(), []string{"start health", "start grpc", "stop grpc", "stop health"}, s.events)
+}
+
+func (s *ManagerTestSuite) TestStop_RespectsStopTimeout() {
+	// Arrange
+	slow := s.component("slow")
+	slow.StopTimeout = 10 * time.Millisecond
+	slow.Stop = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	s.manager.Add(slow)
+	s.manager.Add(s.component("fast", "slow"))
+	require.NoError(s.T(), s.manager.Start(context.Background()))
+
+	// Act
+	err := s.manager.Stop(context.Background())
+
+	// Assert: ошибка медленного компонента не мешает остановке остальных
+	require.ErrorIs(s.T(), err, context.DeadlineExceeded)
+	assert.Equal(s.T(), []string{"start slow", "start fast", "stop fast"}, s.events)
+}
+
+func (s *ManagerTestSuite) TestStart_InvalidGraph() {
+	testCases := []struct {
+		name       string
+		components []Component
+		expected   string
+	}{
+		{
+			name:       "цикл",
+			components: []Component{s.component("a", "b"), s.component("b", "a")},
+			expected:   "циклическая зависимость",
+		},
+		{
+			name:       "неизвестная зависимость",
+			components: []Component{s.component("a", "missing")},
+			expected:   "незарегистрированного missing",
+		},
+		{
+			name:       "дубликат",
+			components: []Component{s.component("a"), s.component("a")},
+			expected:   "зарегистрирован дважды",
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			manager := New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second)
+			for _, c := range tc.components {
+				manager.Add(c)
+			}
+
+			err := manager.Start(context.Background())
+
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tc.expected)
+		})
+	}
+	assert.Empty(s.T(), s.events)
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"ms_template/internal/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// AccessLog пишет одну запись на gRPC вызов: код, длительность, адрес
+// клиента и размеры сообщений. Успешные вызовы проходят выборку,
+// ошибки и медленные вызовы записываются всегда. Метод, trace_id и
+// остальные поля запроса добавляет ContextHandler.
+type AccessLog struct {
+	log        *slog.Logger
+	sampleRate float64
+	slow       time.Duration
+	random     func() float64
+}
+
+func NewAccessLog(log *slog.Logger, cfg config.AccessLogConfig) *AccessLog {
+	rate := 1.0
+	if cfg.SuccessSampleRate != nil {
+		rate = *cfg.SuccessSampleRate
+	}
+	return &AccessLog{
+		log:        log,
+		sampleRate: rate,
+		slow:       cfg.SlowThreshold,
+		random:     rand.Float64,
+	}
+}
+
+// UnaryServerInterceptor возвращает unary interceptor журнала вызовов
+func (a *AccessLog) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		a.record(ctx, start, err,
+			slog.Int("request_size", messageSize(req)),
+			slog.Int("response_size", messageSize(resp)),
+		)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor возвращает stream interceptor журнала вызовов
+func (a *AccessLog) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		stream := &countingStream{ServerStream: ss}
+		err := handler(srv, stream)
+
+		a.record(ss.Context(), start, err,
+			slog.Int("request_size", stream.receivedBytes),
+			slog.Int("response_size", stream.sentBytes),
+			slog.Int("received_messages", stream.received),
+			slog.Int("sent_messages", stream.sent),
+		)
+		return err
+	}
+}
+
+func (a *AccessLog) record(ctx context.Context, start time.Time, err error, sizes ...slog.Attr) {
+	duration := time.Since(start)
+	code := status.Code(err)
+
+	level := levelFor(code)
+	if level == slog.LevelInfo && !a.sampled(duration) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, len(sizes)+4)
+	attrs = append(attrs,
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(duration.Microseconds())/1000),
+	)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	attrs = append(attrs, sizes...)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+
+	a.log.LogAttrs(ctx, level, "gRPC вызов", attrs...)
+}
+
+// sampled решает, попадает ли успешный вызов в журнал
+func (a *AccessLog) sampled(duration time.Duration) bool {
+	if a.slow > 0 && duration >= a.slow {
+		return true
+	}
+	return a.sampleRate >= 1 || a.random() < a.sampleRate
+}
+
+// levelFor выбирает уровень записи по коду: ошибки сервера - Error,
+// ошибки клиента - Warn, остальное - Info
+func levelFor(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss, codes.Unimplemented:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
+
+func messageSize(m interface{}) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
+
+// countingStream считает сообщения и их размер в обе стороны
+type countingStream struct {
+	grpc.ServerStream
+	received, sent           int
+	receivedBytes, sentBytes int
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+		s.receivedBytes += messageSize(m)
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+		s.sentBytes += messageSize(m)
+	}
+	return err
+}
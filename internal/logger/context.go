@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"ms_template/internal/auth"
+	"ms_template/internal/requestid"
+	"ms_template/internal/tenant"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// Ключи полей, которые ContextHandler берет из контекста
+const (
+	KeyTraceID   = "trace_id"
+	KeySpanID    = "span_id"
+	KeyRequestID = "request_id"
+	KeyUser      = "user"
+	KeyTenant    = "tenant"
+	KeyMethod    = "method"
+)
+
+// ContextHandler добавляет к записи поля запроса из контекста:
+// trace_id и span_id активного span, request_id, пользователя, tenant
+// и gRPC метод. Поля попадают в запись, только если логгер вызван
+// с контекстом (InfoContext, LogAttrs и т.п.), а поле с тем же ключом
+// не задано явно: в записи или через With.
+type ContextHandler struct {
+	next slog.Handler
+	// explicit - ключи из WithAttrs в текущей группе. Поля контекста
+	// попадают в ту же группу, поэтому совпадающие ключи пропускаются.
+	explicit []string
+}
+
+var _ slog.Handler = ContextHandler{}
+
+// NewContextHandler оборачивает handler
+func NewContextHandler(next slog.Handler) ContextHandler {
+	return ContextHandler{next: next}
+}
+
+func (h ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.next.Handle(ctx, r)
+	}
+
+	attrs := contextAttrs(ctx)
+	if len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(h.withoutExplicit(r, attrs)...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	explicit := slices.Clip(h.explicit)
+	for _, a := range attrs {
+		explicit = append(explicit, a.Key)
+	}
+	return ContextHandler{next: h.next.WithAttrs(attrs), explicit: explicit}
+}
+
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	// Ключи внешних групп не пересекаются с полями новой группы
+	return ContextHandler{next: h.next.WithGroup(name)}
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String(KeyTraceID, sc.TraceID().String()),
+			slog.String(KeySpanID, sc.SpanID().String()),
+		)
+	}
+	if id, ok := requestid.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String(KeyRequestID, id))
+	}
+	if p, ok := auth.FromContext(ctx); ok && p.Subject != "" {
+		attrs = append(attrs, slog.String(KeyUser, p.Subject))
+	}
+	if id, ok := tenant.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String(KeyTenant, id))
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		attrs = append(attrs, slog.String(KeyMethod, method))
+	}
+
+	return attrs
+}
+
+// withoutExplicit убирает поля, которые уже заданы явно через With
+// или в самой записи
+func (h ContextHandler) withoutExplicit(r slog.Record, attrs []slog.Attr) []slog.Attr {
+	attrs = slices.DeleteFunc(attrs, func(a slog.Attr) bool {
+		return slices.Contains(h.explicit, a.Key)
+	})
+	if len(attrs) == 0 {
+		return nil
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		for i := range attrs {
+			if attrs[i].Key == a.Key {
+				attrs = append(attrs[:i], attrs[i+1:]...)
+				break
+			}
+		}
+		return len(attrs) > 0
+	})
+	return attrs
+}
@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyLogger - поле с именем логгера, см. Named
+const KeyLogger = "logger"
+
+// Levels управляет уровнями логирования во время работы: уровнем по умолчанию
+// (slog.LevelVar) и переопределениями для именованных логгеров. Имена
+// иерархические через точку: переопределение "ratelimit" действует и на
+// "ratelimit.redis", если у него нет собственного.
+type Levels struct {
+	root *slog.LevelVar
+	base slog.Level // уровень из конфигурации, к нему возвращается Reset("")
+
+	mu        sync.Mutex
+	overrides map[string]LevelOverride
+	rootUntil time.Time
+	timers    map[string]*time.Timer
+	// current - неизменяемая копия overrides для чтения без блокировок
+	current atomic.Pointer[map[string]slog.Level]
+}
+
+// LevelOverride - уровень именованного логгера; ExpiresAt нулевой,
+// если уровень не возвращается автоматически
+type LevelOverride struct {
+	Level     slog.Level
+	ExpiresAt time.Time
+}
+
+// LevelsState - текущие уровни для отображения в admin API
+type LevelsState struct {
+	Default   LevelOverride
+	Overrides map[string]LevelOverride
+}
+
+func NewLevels(base slog.Level) *Levels {
+	l := &Levels{
+		root:      &slog.LevelVar{},
+		base:      base,
+		overrides: make(map[string]LevelOverride),
+		timers:    make(map[string]*time.Timer),
+	}
+	l.root.Set(base)
+	l.publish()
+	return l
+}
+
+// Level возвращает действующий уровень логгера с именем name;
+// пустое имя - логгер по умолчанию
+func (l *Levels) Level(name string) slog.Level {
+	overrides := *l.current.Load()
+	for name != "" {
+		if level, ok := overrides[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return l.root.Level()
+}
+
+// Set меняет уровень логгера name (пустое имя - уровень по умолчанию).
+// При ttl > 0 изменение отменяется автоматически по истечении ttl.
+func (l *Levels) Set(name string, level slog.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if name == "" {
+		l.root.Set(level)
+		l.rootUntil = expires
+	} else {
+		l.overrides[name] = LevelOverride{Level: level, ExpiresAt: expires}
+		l.publish()
+	}
+
+	l.stopTimer(name)
+	if ttl > 0 {
+		l.timers[name] = time.AfterFunc(ttl, func() { l.expire(name, expires) })
+	}
+}
+
+// SetBase меняет уровень из конфигурации. Действующий уровень по умолчанию
+// меняется сразу, если он не изменен временно через Set с ttl; иначе
+// новое значение вступает в силу по истечении ttl.
+func (l *Levels) SetBase(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.base = level
+	if l.rootUntil.IsZero() {
+		l.root.Set(level)
+	}
+}
+
+// Reset возвращает уровень по умолчанию к значению из конфигурации
+// или удаляет переопределение именованного логгера
+func (l *Levels) Reset(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopTimer(name)
+	l.reset(name)
+}
+
+// State возвращает текущие уровни
+func (l *Levels) State() LevelsState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return LevelsState{
+		Default:   LevelOverride{Level: l.root.Level(), ExpiresAt: l.rootUntil},
+		Overrides: maps.Clone(l.overrides),
+	}
+}
+
+// Names возвращает имена логгеров с переопределенным уровнем
+func (s LevelsState) Names() []string {
+	names := make([]string, 0, len(s.Overrides))
+	for name := range s.Overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expire отменяет изменение по таймеру, если его не заменили более новым
+func (l *Levels) expire(name string, expires time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if name == "" && !l.rootUntil.Equal(expires) {
+		return
+	}
+	if o, ok := l.overrides[name]; name != "" && (!ok || !o.ExpiresAt.Equal(expires)) {
+		return
+	}
+	delete(l.timers, name)
+	l.reset(name)
+}
+
+func (l *Levels) reset(name string) {
+	if name == "" {
+		l.root.Set(l.base)
+		l.rootUntil = time.Time{}
+		return
+	}
+	delete(l.overrides, name)
+	l.publish()
+}
+
+func (l *Levels) stopTimer(name string) {
+	if t, ok := l.timers[name]; ok {
+		t.Stop()
+		delete(l.timers, name)
+	}
+}
+
+func (l *Levels) publish() {
+	levels := make(map[string]slog.Level, len(l.overrides))
+	for name, o := range l.overrides {
+		levels[name] = o.Level
+	}
+	l.current.Store(&levels)
+}
+
+// levelHandler отсекает записи ниже уровня своего логгера и, если
+// задан sampler, ограничивает повторяющиеся записи.
+// Вложенный handler должен пропускать все уровни.
+type levelHandler struct {
+	next    slog.Handler
+	levels  *Levels
+	name    string
+	sampler *sampler // nil - без ограничения
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.name) && h.next.Enabled(ctx, level)
+}
+
+func (h levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.sampler != nil && !h.sampler.allow(r) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.next = h.next.WithAttrs(attrs)
+	return h
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	h.next = h.next.WithGroup(name)
+	return h
+}
+
+// Named возвращает логгер с именем name: записи получают поле logger,
+// а уровень логгера можно переопределить через Levels.Set(name, ...).
+// Вызывается для логгера без имени; иерархия задается в самом имени,
+// например "ratelimit.redis".
+func Named(log *slog.Logger, name string) *slog.Logger {
+	h, ok := log.Handler().(levelHandler)
+	if !ok {
+		return log.With(KeyLogger, name)
+	}
+	h.next = h.next.WithAttrs([]slog.Attr{slog.String(KeyLogger, name)})
+	h.name = name
+	return slog.New(h)
+}
+
+// Unsampled возвращает логгер без ограничения повторяющихся записей,
+// например для журнала вызовов с собственной выборкой
+func Unsampled(log *slog.Logger) *slog.Logger {
+	h, ok := log.Handler().(levelHandler)
+	if !ok || h.sampler == nil {
+		return log
+	}
+	h.sampler = nil
+	return slog.New(h)
+}
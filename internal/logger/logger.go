@@ -2,38 +2,97 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"os"
-)
+	"strings"
+	"time"
+
+	"ms_template/internal/config"
 
-const (
-	envLocal = "local"
-	envDev   = "dev"
-	envProd  = "prod"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-func Setup(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envLocal:
-		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+// allLevels пропускает все уровни: уровень проверяет levelHandler
+var allLevels = &slog.HandlerOptions{Level: slog.Level(-1 << 10)}
+
+// Setup создает логгер по конфигурации. Поля запроса (trace_id, request_id,
+// пользователь, метод) добавляются из контекста автоматически.
+// Возвращаемые Levels позволяют менять уровень без перезапуска.
+func Setup(cfg config.LoggingConfig) (*slog.Logger, *Levels, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, nil, fmt.Errorf("неизвестный уровень логирования %q", cfg.Level)
+	}
+
+	out, err := output(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler, err := newHandler(out, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	levels := NewLevels(level)
+	h := levelHandler{next: NewContextHandler(handler), levels: levels}
+	if cfg.Sampling.Enabled {
+		h.sampler = newSampler(cfg.Sampling)
+	}
+
+	return slog.New(h), levels, nil
+}
+
+// output возвращает приемник логов; файл ротируется по размеру и возрасту
+func output(cfg config.LoggingConfig) (io.Writer, error) {
+	switch cfg.Output {
+	case config.LogOutputStdout:
+		return os.Stdout, nil
+	case config.LogOutputStderr:
+		return os.Stderr, nil
+	case config.LogOutputFile:
+		return &lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    cfg.File.MaxSizeMB,
+			MaxAge:     int(math.Ceil(cfg.File.MaxAge.Hours() / 24)),
+			MaxBackups: cfg.File.MaxBackups,
+			Compress:   cfg.File.Compress,
+		}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный вывод логов %q", cfg.Output)
+	}
+}
+
+// newHandler создает handler формата из конфигурации
+func newHandler(out io.Writer, cfg config.LoggingConfig) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: allLevels.Level, AddSource: cfg.AddSource}
+
+	switch cfg.Format {
+	case config.LogFormatJSON:
+		return slog.NewJSONHandler(out, opts), nil
+	case config.LogFormatText:
+		return slog.NewTextHandler(out, opts), nil
+	case config.LogFormatLogfmt:
+		opts.ReplaceAttr = logfmtAttr
+		return slog.NewTextHandler(out, opts), nil
 	default:
-		fmt.Println("Переменная env не распознана, логгер запущен в dev режиме")
-		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
+		return nil, fmt.Errorf("неизвестный формат логов %q", cfg.Format)
 	}
+}
 
-	return log
+// logfmtAttr приводит служебные поля к соглашениям logfmt:
+// ts в RFC3339 UTC и уровень в нижнем регистре
+func logfmtAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String("ts", a.Value.Time().UTC().Format(time.RFC3339Nano))
+	case slog.LevelKey:
+		return slog.String(slog.LevelKey, strings.ToLower(a.Value.String()))
+	}
+	return a
 }
@@ -0,0 +1,309 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"ms_template/internal/auth"
+	"ms_template/internal/config"
+	"ms_template/internal/requestid"
+	"ms_template/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const method = "/notes.Notes/AddNote"
+
+type LoggerTestSuite struct {
+	suite.Suite
+	buf *bytes.Buffer
+	log *slog.Logger
+	ctx context.Context
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
+
+func (s *LoggerTestSuite) SetupTest() {
+	s.buf = &bytes.Buffer{}
+	s.log = slog.New(NewContextHandler(slog.NewJSONHandler(s.buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = requestid.NewContext(ctx, "req-1")
+	ctx = auth.NewContext(ctx, auth.Principal{Subject: "client-a"})
+	ctx = tenant.NewContext(ctx, "tenant-1")
+	ctx = grpc.NewContextWithServerTransportStream(ctx, transportStream{})
+	s.ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+}
+
+func (s *LoggerTestSuite) TestContextHandler_AddsRequestFields() {
+	// Act
+	s.log.InfoContext(s.ctx, "сообщение")
+
+	// Assert
+	entry := s.entries()[0]
+	assert.Equal(s.T(), "4bf92f3577b34da6a3ce929d0e0e4736", entry[KeyTraceID])
+	assert.Equal(s.T(), "00f067aa0ba902b7", entry[KeySpanID])
+	assert.Equal(s.T(), "req-1", entry[KeyRequestID])
+	assert.Equal(s.T(), "client-a", entry[KeyUser])
+	assert.Equal(s.T(), "tenant-1", entry[KeyTenant])
+	assert.Equal(s.T(), method, entry[KeyMethod])
+}
+
+func (s *LoggerTestSuite) TestContextHandler_ExplicitFieldWins() {
+	// Act
+	s.log.With("service", "notes").InfoContext(s.ctx, "сообщение", KeyUser, "explicit")
+
+	// Assert: поле не дублируется и не перезаписывается
+	assert.Equal(s.T(), 1, bytes.Count(s.buf.Bytes(), []byte(`"user"`)))
+	entry := s.entries()[0]
+	assert.Equal(s.T(), "explicit", entry[KeyUser])
+	assert.Equal(s.T(), "notes", entry["service"])
+}
+
+func (s *LoggerTestSuite) TestContextHandler_WithFieldWins() {
+	// Act
+	s.log.With(KeyTenant, "from-with").InfoContext(s.ctx, "сообщение")
+	s.log.WithGroup("req").With(KeyMethod, "grouped").InfoContext(s.ctx, "сообщение")
+
+	// Assert: поле из With не дублируется, в том числе внутри группы
+	lines := bytes.Split(bytes.TrimSpace(s.buf.Bytes()), []byte("\n"))
+	require.Len(s.T(), lines, 2)
+	assert.Equal(s.T(), 1, bytes.Count(lines[0], []byte(`"tenant"`)))
+	assert.Equal(s.T(), 1, bytes.Count(lines[1], []byte(`"method"`)))
+	entries := s.entries()
+	assert.Equal(s.T(), "from-with", entries[0][KeyTenant])
+	group := entries[1]["req"].(map[string]any)
+	assert.Equal(s.T(), "grouped", group[KeyMethod])
+	assert.Equal(s.T(), "req-1", group[KeyRequestID])
+}
+
+func (s *LoggerTestSuite) TestContextHandler_WithoutContext() {
+	// Act
+	s.log.Info("сообщение")
+
+	// Assert
+	entry := s.entries()[0]
+	assert.NotContains(s.T(), entry, KeyTraceID)
+	assert.NotContains(s.T(), entry, KeyRequestID)
+}
+
+func (s *LoggerTestSuite) TestAccessLog_Success() {
+	// Arrange
+	interceptor := NewAccessLog(s.log, config.AccessLogConfig{}).UnaryServerInterceptor()
+
+	// Act
+	_, err := interceptor(s.ctx, wrapperspb.String("hello"), &grpc.UnaryServerInfo{FullMethod: method},
+		func(context.Context, interface{}) (interface{}, error) { return wrapperspb.String("ok"), nil })
+	require.NoError(s.T(), err)
+
+	// Assert
+	entry := s.entries()[0]
+	assert.Equal(s.T(), "INFO", entry["level"])
+	assert.Equal(s.T(), "OK", entry["code"])
+	assert.Equal(s.T(), method, entry[KeyMethod])
+	assert.Equal(s.T(), "10.0.0.1:5000", entry["peer"])
+	assert.Equal(s.T(), float64(7), entry["request_size"])
+	assert.Equal(s.T(), float64(4), entry["response_size"])
+	assert.Contains(s.T(), entry, "duration_ms")
+}
+
+func (s *LoggerTestSuite) TestAccessLog_Sampling() {
+	// Arrange: успешные вызовы не проходят выборку
+	rate := 0.0
+	accessLog := NewAccessLog(s.log, config.AccessLogConfig{SuccessSampleRate: &rate, SlowThreshold: 20 * time.Millisecond})
+	interceptor := accessLog.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	// Act
+	interceptor(s.ctx, nil, info, func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+	interceptor(s.ctx, nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.ResourceExhausted, "лимит")
+	})
+	interceptor(s.ctx, nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "сбой")
+	})
+	interceptor(s.ctx, nil, info, func(context.Context, interface{}) (interface{}, error) {
+		time.Sleep(25 * time.Millisecond)
+		return nil, nil
+	})
+
+	// Assert: ошибки и медленный вызов записаны, быстрый успешный - нет
+	entries := s.entries()
+	require.Len(s.T(), entries, 3)
+	assert.Equal(s.T(), "WARN", entries[0]["level"])
+	assert.Equal(s.T(), "ResourceExhausted", entries[0]["code"])
+	assert.Equal(s.T(), "лимит", entries[0]["error"])
+	assert.Equal(s.T(), "ERROR", entries[1]["level"])
+	assert.Equal(s.T(), "OK", entries[2]["code"])
+}
+
+func (s *LoggerTestSuite) entries() []map[string]any {
+	var entries []map[string]any
+	decoder := json.NewDecoder(s.buf)
+	for decoder.More() {
+		var entry map[string]any
+		require.NoError(s.T(), decoder.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	require.NotEmpty(s.T(), entries)
+	return entries
+}
+
+// transportStream позволяет grpc.Method определить метод вне реального сервера
+type transportStream struct{}
+
+func (transportStream) Method() string               { return method }
+func (transportStream) SetHeader(metadata.MD) error  { return nil }
+func (transportStream) SendHeader(metadata.MD) error { return nil }
+func (transportStream) SetTrailer(metadata.MD) error { return nil }
+
+func (s *LoggerTestSuite) TestLevels_NamedOverrides() {
+	// Arrange
+	levels := NewLevels(slog.LevelInfo)
+	log := slog.New(levelHandler{next: slog.NewJSONHandler(s.buf, allLevels), levels: levels})
+	redis := Named(log, "ratelimit.redis")
+
+	// Act: переопределение родителя действует на вложенный логгер
+	levels.Set("ratelimit", slog.LevelDebug, 0)
+	redis.Debug("видно")
+	log.Debug("не видно")
+
+	// Assert
+	entries := s.entries()
+	require.Len(s.T(), entries, 1)
+	assert.Equal(s.T(), "видно", entries[0]["msg"])
+	assert.Equal(s.T(), "ratelimit.redis", entries[0][KeyLogger])
+	assert.Equal(s.T(), slog.LevelDebug, levels.Level("ratelimit.redis"))
+	assert.Equal(s.T(), slog.LevelInfo, levels.Level("ratelimitx"))
+}
+
+func (s *LoggerTestSuite) TestLevels_TTLReverts() {
+	// Arrange
+	levels := NewLevels(slog.LevelInfo)
+
+	// Act
+	levels.Set("", slog.LevelDebug, 20*time.Millisecond)
+	levels.Set("access", slog.LevelError, 20*time.Millisecond)
+
+	// Assert
+	assert.Equal(s.T(), slog.LevelDebug, levels.Level(""))
+	assert.False(s.T(), levels.State().Default.ExpiresAt.IsZero())
+	assert.Eventually(s.T(), func() bool {
+		return levels.Level("") == slog.LevelInfo && len(levels.State().Overrides) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func (s *LoggerTestSuite) TestLevels_SetReplacesPendingRevert() {
+	// Arrange
+	levels := NewLevels(slog.LevelInfo)
+	levels.Set("", slog.LevelDebug, 10*time.Millisecond)
+
+	// Act: постоянное изменение отменяет таймер предыдущего
+	levels.Set("", slog.LevelWarn, 0)
+	time.Sleep(30 * time.Millisecond)
+
+	// Assert
+	assert.Equal(s.T(), slog.LevelWarn, levels.Level(""))
+
+	levels.Reset("")
+	assert.Equal(s.T(), slog.LevelInfo, levels.Level(""))
+}
+
+func (s *LoggerTestSuite) TestSampler_LimitsRepeatedMessages() {
+	// Arrange
+	now := time.Unix(0, 0)
+	sampler := newSampler(config.LogSamplingConfig{Tick: time.Second, First: 2, Thereafter: 3})
+	sampler.now = func() time.Time { return now }
+	log := slog.New(levelHandler{next: slog.NewJSONHandler(s.buf, allLevels), levels: NewLevels(slog.LevelInfo), sampler: sampler})
+
+	// Act: 2 первых + каждое 3-е из оставшихся 6, ошибки не ограничиваются
+	for range 8 {
+		log.Info("повтор")
+		log.Error("ошибка")
+	}
+	log.Info("другое")
+	now = now.Add(time.Second)
+	log.Info("повтор")
+	Unsampled(log).Info("повтор")
+
+	// Assert
+	counts := map[string]int{}
+	for _, entry := range s.entries() {
+		counts[entry["msg"].(string)]++
+	}
+	assert.Equal(s.T(), map[string]int{"повтор": 6, "ошибка": 8, "другое": 1}, counts)
+}
+
+func (s *LoggerTestSuite) TestSetup_FileLogfmt() {
+	// Arrange
+	path := s.T().TempDir() + "/service.log"
+	cfg := config.LoggingConfig{
+		Level:  "warn",
+		Format: config.LogFormatLogfmt,
+		Output: config.LogOutputFile,
+		File:   config.LogFileConfig{Path: path, MaxSizeMB: 1},
+	}
+
+	// Act
+	log, levels, err := Setup(cfg)
+	require.NoError(s.T(), err)
+	log.Info("не видно")
+	log.Warn("видно", "key", "value")
+
+	// Assert
+	assert.Equal(s.T(), slog.LevelWarn, levels.Level(""))
+	data, err := os.ReadFile(path)
+	require.NoError(s.T(), err)
+	assert.Regexp(s.T(), `^ts=\S+Z level=warn msg=видно key=value\n$`, string(data))
+}
+
+func (s *LoggerTestSuite) TestSetup_InvalidConfig() {
+	// Act
+	_, _, levelErr := Setup(config.LoggingConfig{Level: "verbose", Format: config.LogFormatJSON, Output: config.LogOutputStdout})
+	_, _, formatErr := Setup(config.LoggingConfig{Level: "info", Format: "xml", Output: config.LogOutputStdout})
+
+	// Assert
+	assert.Error(s.T(), levelErr)
+	assert.Error(s.T(), formatErr)
+}
+
+func (s *LoggerTestSuite) TestLevels_SetBase() {
+	// Arrange
+	levels := NewLevels(slog.LevelInfo)
+
+	// Act: новое значение из конфигурации применяется сразу
+	levels.SetBase(slog.LevelWarn)
+	afterBase := levels.Level("")
+
+	// временное изменение сохраняется до истечения ttl
+	levels.Set("", slog.LevelDebug, 20*time.Millisecond)
+	levels.SetBase(slog.LevelError)
+
+	// Assert
+	assert.Equal(s.T(), slog.LevelWarn, afterBase)
+	assert.Equal(s.T(), slog.LevelDebug, levels.Level(""))
+	assert.Eventually(s.T(), func() bool {
+		return levels.Level("") == slog.LevelError
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"ms_template/internal/config"
+)
+
+// samplerSlots - число счетчиков; разные сообщения с совпавшим хешем
+// делят счетчик, что допустимо для ограничения объема логов
+const samplerSlots = 4096
+
+// sampler ограничивает повторяющиеся записи: за каждый интервал
+// пропускаются первые first записей с одинаковыми уровнем и сообщением,
+// затем каждая thereafter-я. Записи уровня Error и выше не ограничиваются.
+type sampler struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	now        func() time.Time
+	counters   [samplerSlots]counter
+}
+
+type counter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func newSampler(cfg config.LogSamplingConfig) *sampler {
+	return &sampler{
+		tick:       cfg.Tick,
+		first:      uint64(cfg.First),
+		thereafter: uint64(cfg.Thereafter),
+		now:        time.Now,
+	}
+}
+
+func (s *sampler) allow(r slog.Record) bool {
+	if r.Level >= slog.LevelError {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte{byte(r.Level)})
+	h.Write([]byte(r.Message))
+	c := &s.counters[h.Sum32()%samplerSlots]
+
+	now := s.now().UnixNano()
+	resetAt := c.resetAt.Load()
+	if now >= resetAt && c.resetAt.CompareAndSwap(resetAt, now+int64(s.tick)) {
+		c.count.Store(0)
+	}
+
+	n := c.count.Add(1)
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
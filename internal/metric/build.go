@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"strconv"
+
+	"ms_template/internal/buildinfo"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// initializeBuildInfoMetric регистрирует build_info: метрика всегда
+// равна 1, сведения о сборке передаются метками
+func (m *Metrics) initializeBuildInfoMetric(appName string, info buildinfo.Info) {
+	promauto.With(m.registry).NewGauge(prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Build information of the running binary, value is always 1",
+		ConstLabels: prometheus.Labels{
+			"app":        appName,
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"date":       info.Date,
+			"modified":   strconv.FormatBool(info.Modified),
+			"go_version": info.GoVersion,
+		},
+	}).Set(1)
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// configMetrics - результаты перезагрузки конфигурации
+type configMetrics struct {
+	reloadsTotal      *prometheus.CounterVec
+	lastReloadSuccess prometheus.Gauge
+}
+
+// initializeConfigMetrics инициализирует метрики перезагрузки конфигурации
+func (m *Metrics) initializeConfigMetrics(appName string) {
+	constLabels := prometheus.Labels{"app": appName}
+
+	m.config = configMetrics{
+		reloadsTotal: promauto.With(m.registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "config_reloads_total",
+				Help:        "Total number of configuration reloads by result",
+				ConstLabels: constLabels,
+			},
+			[]string{"result"},
+		),
+		lastReloadSuccess: promauto.With(m.registry).NewGauge(
+			prometheus.GaugeOpts{
+				Name:        "config_last_reload_successful",
+				Help:        "Whether the last configuration reload succeeded (1) or failed (0)",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+	m.config.lastReloadSuccess.Set(1)
+}
+
+// ConfigReloaded регистрирует результат перезагрузки конфигурации
+func (m *Metrics) ConfigReloaded(success bool) {
+	if success {
+		m.config.reloadsTotal.WithLabelValues("success").Inc()
+		m.config.lastReloadSuccess.Set(1)
+		return
+	}
+	m.config.reloadsTotal.WithLabelValues("failure").Inc()
+	m.config.lastReloadSuccess.Set(0)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// httpMetrics - метрики служебного HTTP сервера (метрики, health, пробы)
+type httpMetrics struct {
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight prometheus.Gauge
+}
+
+// initializeHTTPMetrics инициализирует метрики HTTP сервера
+func (m *Metrics) initializeHTTPMetrics(appName string) {
+	constLabels := prometheus.Labels{"app": appName}
+
+	m.http = httpMetrics{
+		requestsTotal: promauto.With(m.registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "http_requests_total",
+				Help:        "Total number of HTTP requests to the service endpoints",
+				ConstLabels: constLabels,
+			},
+			[]string{"handler", "method", "code"},
+		),
+		requestDuration: promauto.With(m.registry).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:        "http_request_duration_seconds",
+				Help:        "HTTP request duration in seconds",
+				Buckets:     []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+				ConstLabels: constLabels,
+			},
+			[]string{"handler", "method", "code"},
+		),
+		requestsInFlight: promauto.With(m.registry).NewGauge(
+			prometheus.GaugeOpts{
+				Name:        "http_requests_in_flight",
+				Help:        "Current number of HTTP requests being served",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+}
+
+// InstrumentHandler оборачивает HTTP обработчик метриками.
+// handler - фиксированное имя эндпоинта, а не путь запроса,
+// чтобы произвольные URL не увеличивали кардинальность.
+func (m *Metrics) InstrumentHandler(handler string, next http.Handler) http.Handler {
+	labels := prometheus.Labels{"handler": handler}
+
+	return promhttp.InstrumentHandlerInFlight(m.http.requestsInFlight,
+		promhttp.InstrumentHandlerCounter(m.http.requestsTotal.MustCurryWith(labels),
+			promhttp.InstrumentHandlerDuration(m.http.requestDuration.MustCurryWith(labels), next),
+		),
+	)
+}
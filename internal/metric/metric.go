@@ -3,8 +3,12 @@ package metrics
 import (
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
+	"ms_template/internal/buildinfo"
+	"ms_template/internal/tenant"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -24,13 +28,38 @@ type Metrics struct {
 	// Бизнес-метрики
 	activeConnections prometheus.Gauge
 	errorsTotal       *prometheus.CounterVec
+	throttledTotal    *prometheus.CounterVec
+	deadlineTotal     *prometheus.CounterVec
+
+	// Метрики транспорта из stats.Handler
+	transport transportMetrics
+
+	// Метрики служебного HTTP сервера
+	http httpMetrics
+
+	// Метрики перезагрузки конфигурации
+	config configMetrics
 
 	// Регистр
 	registry *prometheus.Registry
+
+	// nil, если метка tenant отключена
+	tenants *tenantGuard
+}
+
+// Options - дополнительные настройки метрик
+type Options struct {
+	// TenantLabel добавляет метку tenant к метрикам запросов
+	TenantLabel bool
+	// MaxTenants ограничивает число различных значений метки tenant
+	MaxTenants int
 }
 
+// otherTenant - значение метки для tenant сверх лимита MaxTenants
+const otherTenant = "__other__"
+
 // New создает и регистрирует метрики
-func New(appName string) *Metrics {
+func New(appName string, opts Options) *Metrics {
 	registry := prometheus.NewRegistry()
 
 	// Регистрируем стандартные коллекторы
@@ -42,14 +71,27 @@ func New(appName string) *Metrics {
 	m := &Metrics{
 		registry: registry,
 	}
+	if opts.TenantLabel {
+		m.tenants = newTenantGuard(opts.MaxTenants)
+	}
 
 	m.initializeGRPCMetrics(appName)
+	m.initializeTransportMetrics(appName)
+	m.initializeHTTPMetrics(appName)
+	m.initializeConfigMetrics(appName)
+	m.initializeBuildInfoMetric(appName, buildinfo.Get())
 	return m
 }
 
 // initializeGRPCMetrics инициализирует gRPC метрики
 func (m *Metrics) initializeGRPCMetrics(appName string) {
 	constLabels := prometheus.Labels{"app": appName}
+	withTenant := func(labels ...string) []string {
+		if m.tenants != nil {
+			labels = append(labels, "tenant")
+		}
+		return labels
+	}
 
 	// Общее количество gRPC запросов
 	m.grpcRequestsTotal = promauto.With(m.registry).NewCounterVec(
@@ -58,7 +100,7 @@ func (m *Metrics) initializeGRPCMetrics(appName string) {
 			Help:        "Total number of gRPC requests",
 			ConstLabels: constLabels,
 		},
-		[]string{"method", "code"},
+		withTenant("method", "code"),
 	)
 
 	// Длительность gRPC запросов
@@ -69,7 +111,7 @@ func (m *Metrics) initializeGRPCMetrics(appName string) {
 			Buckets:     []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
 			ConstLabels: constLabels,
 		},
-		[]string{"method", "code"},
+		withTenant("method", "code"),
 	)
 
 	// Текущие выполняющиеся gRPC запросы
@@ -79,7 +121,7 @@ func (m *Metrics) initializeGRPCMetrics(appName string) {
 			Help:        "Current number of gRPC requests being served",
 			ConstLabels: constLabels,
 		},
-		[]string{"method"},
+		withTenant("method"),
 	)
 
 	// Активные соединения
@@ -98,12 +140,37 @@ func (m *Metrics) initializeGRPCMetrics(appName string) {
 			Help:        "Total number of gRPC errors",
 			ConstLabels: constLabels,
 		},
-		[]string{"method", "type"},
+		withTenant("method", "type"),
+	)
+
+	// Запросы, отклоненные ограничителем частоты
+	m.throttledTotal = promauto.With(m.registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "grpc_throttled_requests_total",
+			Help:        "Total number of gRPC requests rejected by the rate limiter",
+			ConstLabels: constLabels,
+		},
+		withTenant("method"),
+	)
+
+	// Вызовы, не уложившиеся в дедлайн клиента или сервера
+	m.deadlineTotal = promauto.With(m.registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "grpc_deadline_exceeded_total",
+			Help:        "Total number of gRPC requests that exceeded their deadline, by deadline source",
+			ConstLabels: constLabels,
+		},
+		withTenant("method", "source"),
 	)
 }
 
+// TenantFunc определяет tenant вызова для метки tenant. Метрики стоят
+// перед tenant interceptor, чтобы учитывать и отклоненные им вызовы,
+// поэтому tenant вычисляется отдельно; пустая строка - tenant неизвестен.
+type TenantFunc func(ctx context.Context) string
+
 // UnaryServerInterceptor возвращает interceptor для gRPC метрик
-func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+func (m *Metrics) UnaryServerInterceptor(tenantOf TenantFunc) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
 		req interface{},
@@ -112,10 +179,11 @@ func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	) (interface{}, error) {
 		start := time.Now()
 		method := info.FullMethod
+		tenantLabel := m.callTenantLabel(ctx, tenantOf)
 
 		// Увеличиваем счетчик текущих запросов
-		m.grpcRequestsInFlight.WithLabelValues(method).Inc()
-		defer m.grpcRequestsInFlight.WithLabelValues(method).Dec()
+		m.grpcRequestsInFlight.WithLabelValues(m.labels(tenantLabel, method)...).Inc()
+		defer m.grpcRequestsInFlight.WithLabelValues(m.labels(tenantLabel, method)...).Dec()
 
 		// Обрабатываем запрос
 		resp, err := handler(ctx, req)
@@ -128,28 +196,28 @@ func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 			} else {
 				code = codes.Unknown
 			}
-			
+
 			// Регистрируем ошибку
 			errorType := "business"
 			if code == codes.Internal || code == codes.Unavailable {
 				errorType = "internal"
 			}
-			m.errorsTotal.WithLabelValues(method, errorType).Inc()
+			m.errorsTotal.WithLabelValues(m.labels(tenantLabel, method, errorType)...).Inc()
 		}
 
 		// Регистрируем метрики
 		duration := time.Since(start).Seconds()
 		codeStr := code.String()
 
-		m.grpcRequestsTotal.WithLabelValues(method, codeStr).Inc()
-		m.grpcRequestDuration.WithLabelValues(method, codeStr).Observe(duration)
+		m.grpcRequestsTotal.WithLabelValues(m.labels(tenantLabel, method, codeStr)...).Inc()
+		m.grpcRequestDuration.WithLabelValues(m.labels(tenantLabel, method, codeStr)...).Observe(duration)
 
 		return resp, err
 	}
 }
 
 // StreamServerInterceptor возвращает stream interceptor для gRPC метрик
-func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+func (m *Metrics) StreamServerInterceptor(tenantOf TenantFunc) grpc.StreamServerInterceptor {
 	return func(
 		srv interface{},
 		ss grpc.ServerStream,
@@ -158,10 +226,11 @@ func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
 	) error {
 		start := time.Now()
 		method := info.FullMethod
+		tenantLabel := m.callTenantLabel(ss.Context(), tenantOf)
 
 		// Увеличиваем счетчик текущих запросов
-		m.grpcRequestsInFlight.WithLabelValues(method).Inc()
-		defer m.grpcRequestsInFlight.WithLabelValues(method).Dec()
+		m.grpcRequestsInFlight.WithLabelValues(m.labels(tenantLabel, method)...).Inc()
+		defer m.grpcRequestsInFlight.WithLabelValues(m.labels(tenantLabel, method)...).Dec()
 
 		// Обрабатываем запрос
 		err := handler(srv, ss)
@@ -174,21 +243,21 @@ func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
 			} else {
 				code = codes.Unknown
 			}
-			
+
 			// Регистрируем ошибку
 			errorType := "business"
 			if code == codes.Internal || code == codes.Unavailable {
 				errorType = "internal"
 			}
-			m.errorsTotal.WithLabelValues(method, errorType).Inc()
+			m.errorsTotal.WithLabelValues(m.labels(tenantLabel, method, errorType)...).Inc()
 		}
 
 		// Регистрируем метрики
 		duration := time.Since(start).Seconds()
 		codeStr := code.String()
 
-		m.grpcRequestsTotal.WithLabelValues(method, codeStr).Inc()
-		m.grpcRequestDuration.WithLabelValues(method, codeStr).Observe(duration)
+		m.grpcRequestsTotal.WithLabelValues(m.labels(tenantLabel, method, codeStr)...).Inc()
+		m.grpcRequestDuration.WithLabelValues(m.labels(tenantLabel, method, codeStr)...).Observe(duration)
 
 		return err
 	}
@@ -205,16 +274,94 @@ func (m *Metrics) ConnectionClosed() {
 }
 
 // ReportError регистрирует ошибку
-func (m *Metrics) ReportError(method, errorType string) {
-	m.errorsTotal.WithLabelValues(method, errorType).Inc()
+func (m *Metrics) ReportError(ctx context.Context, method, errorType string) {
+	m.errorsTotal.WithLabelValues(m.labels(m.tenantLabel(ctx), method, errorType)...).Inc()
+}
+
+// RequestThrottled регистрирует запрос, отклоненный ограничителем частоты
+func (m *Metrics) RequestThrottled(ctx context.Context, method string) {
+	m.throttledTotal.WithLabelValues(m.labels(m.tenantLabel(ctx), method)...).Inc()
+}
+
+// DeadlineExceeded регистрирует вызов, прерванный по дедлайну.
+// source - client или server, в зависимости от того, чей дедлайн действовал.
+func (m *Metrics) DeadlineExceeded(ctx context.Context, method, source string) {
+	m.deadlineTotal.WithLabelValues(m.labels(m.tenantLabel(ctx), method, source)...).Inc()
 }
 
-// Handler возвращает http.Handler для метрик Prometheus
+// Handler возвращает http.Handler для метрик Prometheus из собственного регистра.
+// Ошибки сбора попадают в promhttp_metric_handler_errors_total.
 func (m *Metrics) Handler() http.Handler {
-	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	return promhttp.InstrumentMetricHandler(m.registry,
+		promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry}),
+	)
 }
 
-// GetRegistry возвращает регистр метрик (для кастомных метрик)
+// GetRegistry возвращает регистр метрик (для кастомных метрик и метрик
+// других подсистем, чтобы они публиковались на том же /metrics)
 func (m *Metrics) GetRegistry() *prometheus.Registry {
 	return m.registry
 }
+
+// tenantLabel возвращает значение метки tenant для запроса
+func (m *Metrics) tenantLabel(ctx context.Context) string {
+	if m.tenants == nil {
+		return ""
+	}
+	id, _ := tenant.FromContext(ctx)
+	return m.tenants.label(id)
+}
+
+// callTenantLabel возвращает метку tenant вызова. tenantOf вызывается,
+// только если метка включена и tenant еще не сохранен в контексте.
+func (m *Metrics) callTenantLabel(ctx context.Context, tenantOf TenantFunc) string {
+	if m.tenants == nil {
+		return ""
+	}
+	id, ok := tenant.FromContext(ctx)
+	if !ok && tenantOf != nil {
+		id = tenantOf(ctx)
+	}
+	return m.tenants.label(id)
+}
+
+// labels добавляет метку tenant к значениям, если она включена
+func (m *Metrics) labels(tenantLabel string, values ...string) []string {
+	if m.tenants == nil {
+		return values
+	}
+	return append(values, tenantLabel)
+}
+
+// tenantGuard ограничивает кардинальность метки tenant:
+// первые max tenant получают собственное значение, остальные - общее
+type tenantGuard struct {
+	mu   sync.RWMutex
+	max  int
+	seen map[string]struct{}
+}
+
+func newTenantGuard(max int) *tenantGuard {
+	return &tenantGuard{max: max, seen: make(map[string]struct{})}
+}
+
+func (g *tenantGuard) label(id string) string {
+	g.mu.RLock()
+	_, ok := g.seen[id]
+	g.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[id]; ok {
+		return id
+	}
+	if len(g.seen) >= g.max {
+		return otherTenant
+	}
+	g.seen[id] = struct{}{}
+	return id
+}
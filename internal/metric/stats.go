@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	directionReceived = "received"
+	directionSent     = "sent"
+)
+
+// transportMetrics - метрики транспортного уровня, собираемые stats.Handler:
+// соединения, байты и сообщения. Метка tenant здесь не используется:
+// stats.Handler вызывается до interceptor, определяющего tenant.
+type transportMetrics struct {
+	connectionsTotal prometheus.Counter
+	bytesTotal       *prometheus.CounterVec
+	messagesTotal    *prometheus.CounterVec
+	messageSize      *prometheus.HistogramVec
+	streamMessages   *prometheus.HistogramVec
+}
+
+// initializeTransportMetrics инициализирует метрики stats.Handler
+func (m *Metrics) initializeTransportMetrics(appName string) {
+	constLabels := prometheus.Labels{"app": appName}
+
+	m.transport = transportMetrics{
+		connectionsTotal: promauto.With(m.registry).NewCounter(
+			prometheus.CounterOpts{
+				Name:        "grpc_connections_total",
+				Help:        "Total number of accepted gRPC connections",
+				ConstLabels: constLabels,
+			},
+		),
+		bytesTotal: promauto.With(m.registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "grpc_transport_bytes_total",
+				Help:        "Total number of gRPC payload bytes on the wire, including gRPC framing",
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "direction"},
+		),
+		messagesTotal: promauto.With(m.registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "grpc_messages_total",
+				Help:        "Total number of gRPC messages",
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "direction"},
+		),
+		messageSize: promauto.With(m.registry).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:        "grpc_message_size_bytes",
+				Help:        "Uncompressed size of gRPC messages in bytes",
+				Buckets:     prometheus.ExponentialBuckets(64, 4, 9), // 64 B .. 4 MiB
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "direction"},
+		),
+		streamMessages: promauto.With(m.registry).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:        "grpc_stream_messages",
+				Help:        "Number of messages per streaming gRPC call",
+				Buckets:     prometheus.ExponentialBuckets(1, 4, 8), // 1 .. 16384
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "direction"},
+		),
+	}
+}
+
+// StatsHandler возвращает stats.Handler для регистрации на сервере
+// через grpc.StatsHandler. Он считает соединения, байты и сообщения,
+// в том числе отдельные сообщения внутри stream.
+func (m *Metrics) StatsHandler() stats.Handler {
+	return &statsHandler{m: m}
+}
+
+type statsHandler struct {
+	m *Metrics
+}
+
+type rpcStatsKey struct{}
+
+// rpcStats накапливает число сообщений одного вызова
+type rpcStats struct {
+	method   string
+	stream   bool
+	received atomic.Int64
+	sent     atomic.Int64
+}
+
+func (h *statsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *statsHandler) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.m.transport.connectionsTotal.Inc()
+		h.m.ConnectionOpened()
+	case *stats.ConnEnd:
+		h.m.ConnectionClosed()
+	}
+}
+
+func (h *statsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, rpcStatsKey{}, &rpcStats{method: info.FullMethodName})
+}
+
+func (h *statsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	rs, ok := ctx.Value(rpcStatsKey{}).(*rpcStats)
+	if !ok || s.IsClient() {
+		return
+	}
+
+	t := h.m.transport
+	switch s := s.(type) {
+	case *stats.Begin:
+		rs.stream = s.IsClientStream || s.IsServerStream
+	case *stats.InPayload:
+		rs.received.Add(1)
+		t.messagesTotal.WithLabelValues(rs.method, directionReceived).Inc()
+		t.bytesTotal.WithLabelValues(rs.method, directionReceived).Add(float64(s.WireLength))
+		t.messageSize.WithLabelValues(rs.method, directionReceived).Observe(float64(s.Length))
+	case *stats.OutPayload:
+		rs.sent.Add(1)
+		t.messagesTotal.WithLabelValues(rs.method, directionSent).Inc()
+		t.bytesTotal.WithLabelValues(rs.method, directionSent).Add(float64(s.WireLength))
+		t.messageSize.WithLabelValues(rs.method, directionSent).Observe(float64(s.Length))
+	case *stats.End:
+		// Для unary вызова число сообщений всегда 1, гистограмма имеет смысл только для stream
+		if rs.stream {
+			t.streamMessages.WithLabelValues(rs.method, directionReceived).Observe(float64(rs.received.Load()))
+			t.streamMessages.WithLabelValues(rs.method, directionSent).Observe(float64(rs.sent.Load()))
+		}
+	}
+}
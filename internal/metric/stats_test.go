@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/stats"
+)
+
+type StatsHandlerTestSuite struct {
+	suite.Suite
+	metrics *Metrics
+	handler stats.Handler
+}
+
+func TestStatsHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(StatsHandlerTestSuite))
+}
+
+func (s *StatsHandlerTestSuite) SetupTest() {
+	s.metrics = New("test", Options{})
+	s.handler = s.metrics.StatsHandler()
+}
+
+func (s *StatsHandlerTestSuite) TestHandleConn_TracksActiveConnections() {
+	// Act
+	ctx := s.handler.TagConn(context.Background(), &stats.ConnTagInfo{})
+	s.handler.HandleConn(ctx, &stats.ConnBegin{})
+	s.handler.HandleConn(ctx, &stats.ConnBegin{})
+	s.handler.HandleConn(ctx, &stats.ConnEnd{})
+
+	// Assert
+	assert.Equal(s.T(), 1.0, testutil.ToFloat64(s.metrics.activeConnections))
+	assert.Equal(s.T(), 2.0, testutil.ToFloat64(s.metrics.transport.connectionsTotal))
+}
+
+func (s *StatsHandlerTestSuite) TestHandleRPC_Unary() {
+	// Arrange
+	const method = "/notes.Notes/AddNote"
+	ctx := s.handler.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: method})
+
+	// Act
+	s.handler.HandleRPC(ctx, &stats.Begin{})
+	s.handler.HandleRPC(ctx, &stats.InPayload{Length: 100, WireLength: 105})
+	s.handler.HandleRPC(ctx, &stats.OutPayload{Length: 20, WireLength: 25})
+	s.handler.HandleRPC(ctx, &stats.End{})
+
+	// Assert
+	t := s.metrics.transport
+	assert.Equal(s.T(), 105.0, testutil.ToFloat64(t.bytesTotal.WithLabelValues(method, directionReceived)))
+	assert.Equal(s.T(), 25.0, testutil.ToFloat64(t.bytesTotal.WithLabelValues(method, directionSent)))
+	assert.Equal(s.T(), 1.0, testutil.ToFloat64(t.messagesTotal.WithLabelValues(method, directionReceived)))
+	assert.Equal(s.T(), 2, testutil.CollectAndCount(t.messageSize), "по серии на каждое направление")
+	assert.Equal(s.T(), 0, testutil.CollectAndCount(t.streamMessages), "unary вызов не попадает в гистограмму stream")
+}
+
+func (s *StatsHandlerTestSuite) TestHandleRPC_StreamCountsMessages() {
+	// Arrange
+	const method = "/notes.Notes/Watch"
+	ctx := s.handler.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: method})
+
+	// Act: клиент отправил одно сообщение, сервер ответил тремя
+	s.handler.HandleRPC(ctx, &stats.Begin{IsServerStream: true})
+	s.handler.HandleRPC(ctx, &stats.InPayload{Length: 10, WireLength: 15})
+	for range 3 {
+		s.handler.HandleRPC(ctx, &stats.OutPayload{Length: 10, WireLength: 15})
+	}
+	s.handler.HandleRPC(ctx, &stats.End{})
+
+	// Assert
+	t := s.metrics.transport
+	assert.Equal(s.T(), 3.0, testutil.ToFloat64(t.messagesTotal.WithLabelValues(method, directionSent)))
+	assert.Equal(s.T(), 45.0, testutil.ToFloat64(t.bytesTotal.WithLabelValues(method, directionSent)))
+	assert.Equal(s.T(), 2, testutil.CollectAndCount(t.streamMessages))
+}
+
+func (s *StatsHandlerTestSuite) TestHandleRPC_IgnoresClientEvents() {
+	// Arrange
+	ctx := s.handler.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: "/notes.Notes/GetNotes"})
+
+	// Act
+	s.handler.HandleRPC(ctx, &stats.OutPayload{Client: true, Length: 10, WireLength: 15})
+
+	// Assert
+	assert.Equal(s.T(), 0, testutil.CollectAndCount(s.metrics.transport.messagesTotal))
+}
@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"ms_template/internal/auth"
+	"ms_template/internal/config"
+	"ms_template/internal/tenant"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Reporter учитывает отклоненные запросы в метриках
+type Reporter interface {
+	RequestThrottled(ctx context.Context, method string)
+}
+
+// Interceptor ограничивает частоту вызовов по правилам из конфигурации
+type Interceptor struct {
+	log      *slog.Logger
+	limiter  Limiter
+	reporter Reporter
+	keyBy    []string
+	rules    atomic.Pointer[rules]
+}
+
+// rules - лимиты методов, заменяются целиком через Update
+type rules struct {
+	fallback *Limit
+	methods  map[string]Limit
+}
+
+func NewInterceptor(log *slog.Logger, limiter Limiter, reporter Reporter, cfg config.RateLimitConfig) *Interceptor {
+	i := &Interceptor{
+		log:      log,
+		limiter:  limiter,
+		reporter: reporter,
+		keyBy:    cfg.KeyBy,
+	}
+	i.Update(cfg)
+
+	return i
+}
+
+// Update применяет правила rate_limit.default и rate_limit.methods из новой
+// конфигурации. Уже накопленное состояние корзин сохраняется.
+func (i *Interceptor) Update(cfg config.RateLimitConfig) {
+	r := &rules{methods: make(map[string]Limit, len(cfg.Methods))}
+	if cfg.Default != nil {
+		r.fallback = &Limit{Rate: cfg.Default.RPS, Burst: cfg.Default.Burst}
+	}
+	for method, rule := range cfg.Methods {
+		r.methods[method] = Limit{Rate: rule.RPS, Burst: rule.Burst}
+	}
+	i.rules.Store(r)
+}
+
+// UnaryServerInterceptor отклоняет unary вызовы сверх лимита
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := i.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor отклоняет открытие стримов сверх лимита
+func (i *Interceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := i.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (i *Interceptor) check(ctx context.Context, method string) error {
+	r := i.rules.Load()
+	limit, ok := r.methods[method]
+	if !ok {
+		if r.fallback == nil {
+			return nil
+		}
+		limit = *r.fallback
+	}
+
+	res, err := i.limiter.Allow(ctx, i.key(ctx, method), limit)
+	if err != nil {
+		// Ошибка лимитера не должна останавливать обслуживание
+		i.log.WarnContext(ctx, "Ошибка лимитера, запрос пропущен", "method", method, "error", err)
+		return nil
+	}
+	if res.Allowed {
+		return nil
+	}
+
+	i.reporter.RequestThrottled(ctx, method)
+	return throttledStatus(res)
+}
+
+// key строит ключ корзины: метод и значения измерений из KeyBy
+func (i *Interceptor) key(ctx context.Context, method string) string {
+	parts := []string{method}
+
+	for _, k := range i.keyBy {
+		switch k {
+		case config.RateLimitKeyPrincipal:
+			if p, ok := auth.FromContext(ctx); ok && p.Subject != "" {
+				parts = append(parts, "principal="+p.Subject)
+			} else {
+				// Анонимных клиентов различаем по адресу
+				parts = append(parts, "peer="+peerIP(ctx))
+			}
+		case config.RateLimitKeyTenant:
+			id, _ := tenant.FromContext(ctx)
+			parts = append(parts, "tenant="+id)
+		case config.RateLimitKeyPeer:
+			parts = append(parts, "peer="+peerIP(ctx))
+		case config.RateLimitKeyMethod:
+			// Метод уже входит в ключ
+		}
+	}
+
+	return strings.Join(parts, "|")
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// throttledStatus возвращает ResourceExhausted с подсказкой errdetails.RetryInfo
+func throttledStatus(res Result) error {
+	st := status.New(codes.ResourceExhausted, "превышен лимит частоты запросов")
+	if res.RetryAfter <= 0 {
+		return st.Err()
+	}
+
+	withDetails, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(res.RetryAfter),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,24 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit - параметры token bucket: Rate токенов в секунду, не более Burst подряд
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result - решение лимитера по запросу
+type Result struct {
+	Allowed bool
+	// RetryAfter - через сколько появится токен, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// Limiter расходует один токен из корзины key
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
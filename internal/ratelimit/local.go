@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval - как часто удалять корзины, которые успели заполниться
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	refill time.Duration // время полного пополнения корзины
+}
+
+// Local - token bucket в памяти процесса
+type Local struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+var _ Limiter = &Local{}
+
+func NewLocal() *Local {
+	return &Local{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *Local) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	if limit.Rate > 0 {
+		b.refill = time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+	}
+
+	// Пополняем корзину за прошедшее время
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true}, nil
+	}
+
+	if limit.Rate <= 0 {
+		return Result{Allowed: false}, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return Result{Allowed: false, RetryAfter: wait}, nil
+}
+
+// sweep удаляет корзины, которые не использовались дольше, чем нужно
+// для их полного пополнения, чтобы map не росла с числом ключей.
+// Удаленная корзина неотличима от новой, поэтому лимит не ослабевает.
+func (l *Local) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.refill > 0 && now.Sub(b.last) > b.refill {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ms_template/internal/config"
+	"ms_template/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeReporter struct {
+	throttled []string
+}
+
+func (r *fakeReporter) RequestThrottled(_ context.Context, method string) {
+	r.throttled = append(r.throttled, method)
+}
+
+type LocalLimiterTestSuite struct {
+	suite.Suite
+	limiter *Local
+	now     time.Time
+}
+
+func TestLocalLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalLimiterTestSuite))
+}
+
+func (s *LocalLimiterTestSuite) SetupTest() {
+	s.now = time.Unix(1_700_000_000, 0)
+	s.limiter = NewLocal()
+	s.limiter.now = func() time.Time { return s.now }
+}
+
+func (s *LocalLimiterTestSuite) TestAllow_BurstThenThrottle() {
+	// Arrange
+	limit := Limit{Rate: 2, Burst: 3}
+
+	// Act & Assert
+	for i := 0; i < 3; i++ {
+		res, err := s.limiter.Allow(context.Background(), "key", limit)
+		require.NoError(s.T(), err)
+		assert.True(s.T(), res.Allowed)
+	}
+
+	res, err := s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), res.Allowed)
+	assert.Equal(s.T(), 500*time.Millisecond, res.RetryAfter)
+}
+
+func (s *LocalLimiterTestSuite) TestAllow_Refill() {
+	// Arrange
+	limit := Limit{Rate: 1, Burst: 1}
+	res, _ := s.limiter.Allow(context.Background(), "key", limit)
+	require.True(s.T(), res.Allowed)
+
+	// Act
+	s.now = s.now.Add(time.Second)
+	res, err := s.limiter.Allow(context.Background(), "key", limit)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.True(s.T(), res.Allowed)
+}
+
+func (s *LocalLimiterTestSuite) TestAllow_KeysAreIndependent() {
+	// Arrange
+	limit := Limit{Rate: 1, Burst: 1}
+	res, _ := s.limiter.Allow(context.Background(), "a", limit)
+	require.True(s.T(), res.Allowed)
+
+	// Act
+	res, err := s.limiter.Allow(context.Background(), "b", limit)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.True(s.T(), res.Allowed)
+}
+
+func (s *LocalLimiterTestSuite) TestInterceptor_ThrottlesWithRetryInfo() {
+	// Arrange
+	reporter := &fakeReporter{}
+	interceptor := NewInterceptor(slog.New(slog.NewTextHandler(io.Discard, nil)), s.limiter, reporter, config.RateLimitConfig{
+		Enabled: true,
+		KeyBy:   []string{config.RateLimitKeyTenant},
+		Methods: map[string]config.RateLimitRule{
+			"/notes.Notes/AddNote": {RPS: 1, Burst: 1},
+		},
+	})
+	unary := interceptor.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/notes.Notes/AddNote"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	// Act
+	_, firstErr := unary(ctx, nil, info, handler)
+	_, secondErr := unary(ctx, nil, info, handler)
+	_, otherTenantErr := unary(tenant.NewContext(context.Background(), "other"), nil, info, handler)
+	_, unlimitedErr := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/notes.Notes/GetNotes"}, handler)
+
+	// Assert
+	assert.NoError(s.T(), firstErr)
+	assert.NoError(s.T(), otherTenantErr)
+	assert.NoError(s.T(), unlimitedErr)
+
+	st, ok := status.FromError(secondErr)
+	require.True(s.T(), ok)
+	assert.Equal(s.T(), codes.ResourceExhausted, st.Code())
+	require.Len(s.T(), st.Details(), 1)
+	retry, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(s.T(), ok)
+	assert.Equal(s.T(), time.Second, retry.RetryDelay.AsDuration())
+	assert.Equal(s.T(), []string{"/notes.Notes/AddNote"}, reporter.throttled)
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript реализует GCRA (generic cell rate algorithm).
+// В ключе хранится теоретическое время прибытия (TAT) следующего запроса
+// в микросекундах. Время берется из Redis, чтобы часы реплик не влияли на лимит.
+//
+// KEYS[1] - ключ корзины
+// ARGV[1] - интервал между запросами в микросекундах (1 / rate)
+// ARGV[2] - burst
+//
+// Возвращает {1, 0}, если запрос разрешен, иначе {0, задержка в микросекундах}.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - interval * burst
+if allow_at > now then
+  return {0, allow_at - now}
+end
+
+-- %.0f: иначе Lua запишет большое число в экспоненциальной форме с потерей точности
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {1, 0}
+`)
+
+// Redis - распределенный лимитер, общий для всех реплик.
+// При недоступности Redis решения принимает локальный лимитер,
+// поэтому лимит временно становится per-replica, но не отключается.
+// После ошибки Redis не опрашивается в течение backoff, чтобы сбой
+// не добавлял timeout к каждому вызову.
+type Redis struct {
+	log      *slog.Logger
+	client   redis.UniversalClient
+	fallback Limiter
+	prefix   string
+	timeout  time.Duration
+	backoff  time.Duration
+
+	degraded atomic.Bool  // работаем на локальном лимите
+	retryAt  atomic.Int64 // до этого момента (UnixNano) Redis не опрашивается
+}
+
+var _ Limiter = &Redis{}
+
+func NewRedis(log *slog.Logger, client redis.UniversalClient, fallback Limiter, prefix string, timeout, backoff time.Duration) *Redis {
+	return &Redis{
+		log:      log,
+		client:   client,
+		fallback: fallback,
+		prefix:   prefix,
+		timeout:  timeout,
+		backoff:  backoff,
+	}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	// Некорректное правило и отмененный вызов не говорят о состоянии
+	// Redis и не должны переводить все вызовы на локальный лимит
+	if limit.Rate <= 0 {
+		return Result{}, fmt.Errorf("некорректная частота %v", limit.Rate)
+	}
+	if ctx.Err() != nil {
+		return r.fallback.Allow(ctx, key, limit)
+	}
+	if time.Now().UnixNano() < r.retryAt.Load() {
+		return r.fallback.Allow(ctx, key, limit)
+	}
+
+	res, err := r.allow(ctx, key, limit)
+	if err != nil && ctx.Err() != nil {
+		return r.fallback.Allow(ctx, key, limit)
+	}
+	if err != nil {
+		r.retryAt.Store(time.Now().Add(r.backoff).UnixNano())
+		// Пишем в лог только смену состояния, а не каждый запрос
+		if !r.degraded.Swap(true) {
+			r.log.Warn("Redis лимитер недоступен, используется локальный лимит",
+				"error", err, "retry_after", r.backoff)
+		}
+		return r.fallback.Allow(ctx, key, limit)
+	}
+
+	if r.degraded.Swap(false) {
+		r.log.Info("Redis лимитер снова доступен")
+	}
+	return res, nil
+}
+
+// allow выполняет скрипт GCRA. Скрипт ограничен только timeout, а не
+// контекстом вызова: отключение клиента не должно выглядеть как сбой Redis.
+func (r *Redis) allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+		defer cancel()
+	}
+
+	interval := int64(float64(time.Second/time.Microsecond) / limit.Rate)
+	values, err := gcraScript.Run(ctx, r.client, []string{r.prefix + key}, interval, limit.Burst).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ошибка выполнения скрипта лимитера: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("неожиданный ответ скрипта лимитера: %v", values)
+	}
+
+	if values[0] == 1 {
+		return Result{Allowed: true}, nil
+	}
+	return Result{Allowed: false, RetryAfter: time.Duration(values[1]) * time.Microsecond}, nil
+}
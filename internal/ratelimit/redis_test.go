@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisLimiterTestSuite struct {
+	suite.Suite
+	server  *miniredis.Miniredis
+	client  *redis.Client
+	limiter *Redis
+}
+
+func TestRedisLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisLimiterTestSuite))
+}
+
+func (s *RedisLimiterTestSuite) SetupTest() {
+	s.server = miniredis.RunT(s.T())
+	s.server.SetTime(time.Unix(1_700_000_000, 0))
+	s.client = redis.NewClient(&redis.Options{Addr: s.server.Addr()})
+	s.limiter = NewRedis(slog.New(slog.NewTextHandler(io.Discard, nil)), s.client, NewLocal(), "rl:", time.Second, 0)
+}
+
+func (s *RedisLimiterTestSuite) TearDownTest() {
+	s.client.Close()
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_BurstThenThrottle() {
+	// Arrange
+	limit := Limit{Rate: 2, Burst: 3}
+
+	// Act & Assert
+	for i := 0; i < 3; i++ {
+		res, err := s.limiter.Allow(context.Background(), "key", limit)
+		require.NoError(s.T(), err)
+		assert.True(s.T(), res.Allowed, "запрос %d", i)
+	}
+
+	res, err := s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), res.Allowed)
+	assert.Equal(s.T(), 500*time.Millisecond, res.RetryAfter)
+	assert.True(s.T(), s.server.Exists("rl:key"))
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_Refill() {
+	// Arrange
+	limit := Limit{Rate: 1, Burst: 1}
+	res, err := s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	require.True(s.T(), res.Allowed)
+
+	res, err = s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	require.False(s.T(), res.Allowed)
+
+	// Act
+	s.server.SetTime(time.Unix(1_700_000_001, 0))
+	res, err = s.limiter.Allow(context.Background(), "key", limit)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.True(s.T(), res.Allowed)
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_SharedBetweenReplicas() {
+	// Arrange
+	limit := Limit{Rate: 1, Burst: 1}
+	replica := NewRedis(slog.New(slog.NewTextHandler(io.Discard, nil)), s.client, NewLocal(), "rl:", time.Second, 0)
+
+	// Act
+	first, err := s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	second, err := replica.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+
+	// Assert
+	assert.True(s.T(), first.Allowed)
+	assert.False(s.T(), second.Allowed)
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_FallbackWhenRedisDown() {
+	// Arrange
+	limit := Limit{Rate: 1, Burst: 1}
+	s.server.Close()
+
+	// Act
+	first, err := s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	second, err := s.limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+
+	// Assert: локальный лимит продолжает действовать
+	assert.True(s.T(), first.Allowed)
+	assert.False(s.T(), second.Allowed)
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_SkipsRedisDuringBackoff() {
+	// Arrange
+	limit := Limit{Rate: 100, Burst: 100}
+	limiter := NewRedis(slog.New(slog.NewTextHandler(io.Discard, nil)), s.client, NewLocal(), "rl:", time.Second, 100*time.Millisecond)
+	s.server.Close()
+	_, err := limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.server.Restart())
+
+	// Act: Redis снова доступен, но backoff еще не истек
+	_, err = limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+	skipped := !s.server.Exists("rl:key")
+
+	time.Sleep(150 * time.Millisecond)
+	_, err = limiter.Allow(context.Background(), "key", limit)
+	require.NoError(s.T(), err)
+
+	// Assert
+	assert.True(s.T(), skipped)
+	assert.True(s.T(), s.server.Exists("rl:key"))
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_CanceledCallKeepsRedis() {
+	// Arrange
+	limit := Limit{Rate: 100, Burst: 100}
+	limiter := NewRedis(slog.New(slog.NewTextHandler(io.Discard, nil)), s.client, NewLocal(), "rl:", time.Second, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, errCanceled := limiter.Allow(ctx, "canceled", limit)
+	_, err := limiter.Allow(context.Background(), "key", limit)
+
+	// Assert: отмена вызова не включает backoff
+	require.NoError(s.T(), errCanceled)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), limiter.degraded.Load())
+	assert.True(s.T(), s.server.Exists("rl:key"))
+}
+
+func (s *RedisLimiterTestSuite) TestAllow_InvalidRateKeepsRedis() {
+	// Arrange
+	limiter := NewRedis(slog.New(slog.NewTextHandler(io.Discard, nil)), s.client, NewLocal(), "rl:", time.Second, time.Minute)
+
+	// Act
+	_, errInvalid := limiter.Allow(context.Background(), "invalid", Limit{Rate: 0, Burst: 1})
+	_, err := limiter.Allow(context.Background(), "key", Limit{Rate: 1, Burst: 1})
+
+	// Assert
+	require.Error(s.T(), errInvalid)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), limiter.degraded.Load())
+	assert.True(s.T(), s.server.Exists("rl:key"))
+}
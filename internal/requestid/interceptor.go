@@ -0,0 +1,135 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Header - ключ metadata с идентификатором запроса
+const Header = "x-request-id"
+
+// maxLength ограничивает идентификатор, присланный клиентом
+const maxLength = 128
+
+// spanAttribute - атрибут span с идентификатором запроса
+var spanAttribute = attribute.Key("request.id")
+
+// UnaryServerInterceptor берет идентификатор запроса из metadata или
+// генерирует новый, сохраняет его в контексте и возвращает клиенту в
+// заголовках и trailer. Ошибка вызова дополняется errdetails.RequestInfo.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := incoming(ctx)
+		ctx = NewContext(ctx, id)
+		trace.SpanFromContext(ctx).SetAttributes(spanAttribute.String(id))
+
+		md := metadata.Pairs(Header, id)
+		// Заголовки могут быть уже отправлены, ошибка не влияет на вызов
+		_ = grpc.SetHeader(ctx, md)
+		_ = grpc.SetTrailer(ctx, md)
+
+		resp, err := handler(ctx, req)
+		return resp, withRequestInfo(err, id)
+	}
+}
+
+// StreamServerInterceptor - аналог UnaryServerInterceptor для stream вызовов
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := incoming(ss.Context())
+		ctx := NewContext(ss.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(spanAttribute.String(id))
+
+		md := metadata.Pairs(Header, id)
+		_ = ss.SetHeader(md)
+		ss.SetTrailer(md)
+
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return withRequestInfo(handler(srv, wrapped), id)
+	}
+}
+
+// UnaryClientInterceptor передает идентификатор запроса из контекста
+// в исходящие вызовы, чтобы цепочка сервисов делила один request_id
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor - аналог UnaryClientInterceptor для stream вызовов
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+// incoming возвращает идентификатор из metadata запроса или новый,
+// если клиент его не передал или передал некорректный
+func incoming(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, Header); len(values) > 0 && valid(values[0]) {
+		return values[0]
+	}
+	return uuid.NewString()
+}
+
+// outgoing добавляет идентификатор в исходящую metadata, если его там еще нет
+func outgoing(ctx context.Context) context.Context {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(Header)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, Header, id)
+}
+
+// valid допускает только печатные ASCII символы ограниченной длины,
+// чтобы идентификатор клиента безопасно попадал в логи и заголовки
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// withRequestInfo добавляет идентификатор запроса в детали ошибки,
+// сохраняя код, сообщение и уже добавленные детали. Ошибки без статуса
+// получают код так же, как в grpc-go: context.Canceled - Canceled и т.д.
+func withRequestInfo(err error, id string) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		// Как и grpc-go, ошибки контекста отдаем кодами Canceled и DeadlineExceeded
+		st = status.FromContextError(err)
+	}
+	for _, d := range st.Details() {
+		if _, ok := d.(*errdetails.RequestInfo); ok {
+			return err
+		}
+	}
+
+	withInfo, detailsErr := st.WithDetails(&errdetails.RequestInfo{RequestId: id})
+	if detailsErr != nil {
+		return err
+	}
+	return withInfo.Err()
+}
@@ -0,0 +1,148 @@
+package requestid
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type RequestIDTestSuite struct {
+	suite.Suite
+	client healthpb.HealthClient
+}
+
+func TestRequestIDTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestIDTestSuite))
+}
+
+func (s *RequestIDTestSuite) SetupTest() {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	checks := health.NewServer()
+	checks.SetServingStatus("notes.Notes", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, checks)
+	go server.Serve(listener)
+	s.T().Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	require.NoError(s.T(), err)
+	s.T().Cleanup(func() { conn.Close() })
+	s.client = healthpb.NewHealthClient(conn)
+}
+
+func (s *RequestIDTestSuite) TestGeneratesID() {
+	// Arrange
+	var header, trailer metadata.MD
+
+	// Act
+	_, err := s.client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "notes.Notes"},
+		grpc.Header(&header), grpc.Trailer(&trailer))
+
+	// Assert
+	require.NoError(s.T(), err)
+	require.Len(s.T(), header.Get(Header), 1)
+	_, parseErr := uuid.Parse(header.Get(Header)[0])
+	assert.NoError(s.T(), parseErr)
+	assert.Equal(s.T(), header.Get(Header), trailer.Get(Header))
+}
+
+func (s *RequestIDTestSuite) TestForwardsIDFromContext() {
+	// Arrange: клиентский interceptor передает идентификатор из контекста
+	var header metadata.MD
+	ctx := NewContext(context.Background(), "req-from-upstream")
+
+	// Act
+	_, err := s.client.Check(ctx, &healthpb.HealthCheckRequest{Service: "notes.Notes"}, grpc.Header(&header))
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"req-from-upstream"}, header.Get(Header))
+}
+
+func (s *RequestIDTestSuite) TestRejectsInvalidID() {
+	testCases := map[string]string{
+		"пробелы": "id with spaces",
+		"длинный": strings.Repeat("a", maxLength+1),
+		"пустой":  "",
+	}
+
+	for name, id := range testCases {
+		s.Run(name, func() {
+			var header metadata.MD
+			ctx := metadata.AppendToOutgoingContext(context.Background(), Header, id)
+
+			_, err := s.client.Check(ctx, &healthpb.HealthCheckRequest{Service: "notes.Notes"}, grpc.Header(&header))
+
+			require.NoError(s.T(), err)
+			require.Len(s.T(), header.Get(Header), 1)
+			assert.NotEqual(s.T(), id, header.Get(Header)[0])
+		})
+	}
+}
+
+func (s *RequestIDTestSuite) TestAddsRequestInfoToErrors() {
+	// Arrange
+	ctx := metadata.AppendToOutgoingContext(context.Background(), Header, "req-42")
+
+	// Act: неизвестный сервис возвращает NotFound
+	_, err := s.client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+
+	// Assert
+	st := status.Convert(err)
+	assert.Equal(s.T(), codes.NotFound, st.Code())
+	require.Len(s.T(), st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.RequestInfo)
+	require.True(s.T(), ok)
+	assert.Equal(s.T(), "req-42", info.RequestId)
+}
+
+func (s *RequestIDTestSuite) TestWithRequestInfo_KeepsExistingDetails() {
+	// Arrange
+	st, err := status.New(codes.ResourceExhausted, "лимит").WithDetails(&errdetails.QuotaFailure{})
+	require.NoError(s.T(), err)
+
+	// Act
+	result := status.Convert(withRequestInfo(st.Err(), "req-1"))
+
+	// Assert
+	assert.Equal(s.T(), codes.ResourceExhausted, result.Code())
+	assert.Equal(s.T(), "лимит", result.Message())
+	require.Len(s.T(), result.Details(), 2)
+	assert.IsType(s.T(), &errdetails.QuotaFailure{}, result.Details()[0])
+
+	// Повторное добавление не дублирует деталь
+	again := status.Convert(withRequestInfo(result.Err(), "req-2"))
+	assert.Len(s.T(), again.Details(), 2)
+}
+
+func (s *RequestIDTestSuite) TestWithRequestInfo_ContextErrors() {
+	// Act
+	canceled := status.Convert(withRequestInfo(context.Canceled, "req-1"))
+	expired := status.Convert(withRequestInfo(fmt.Errorf("запрос к хранилищу: %w", context.DeadlineExceeded), "req-1"))
+
+	// Assert
+	assert.Equal(s.T(), codes.Canceled, canceled.Code())
+	assert.Equal(s.T(), codes.DeadlineExceeded, expired.Code())
+	require.Len(s.T(), canceled.Details(), 1)
+	assert.IsType(s.T(), &errdetails.RequestInfo{}, canceled.Details()[0])
+}
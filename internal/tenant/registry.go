@@ -0,0 +1,98 @@
+package tenant
+
+import (
+	"context"
+	"maps"
+	"sync/atomic"
+
+	"ms_template/internal/config"
+)
+
+// Settings - итоговые настройки tenant с учетом переопределений
+type Settings struct {
+	Limits     config.LimitsConfig
+	Features   map[string]bool
+	UserLimits map[string]config.LimitsConfig
+	Quota      config.LimitsConfig
+}
+
+// Registry хранит настройки по умолчанию и переопределения для tenant.
+// Настройки заменяются целиком через Update при перезагрузке конфигурации.
+type Registry struct {
+	state atomic.Pointer[registryState]
+}
+
+type registryState struct {
+	defaults  Settings
+	overrides map[string]config.TenantConfig
+}
+
+// NewRegistry строит Registry из конфигурации
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{}
+	r.Update(cfg)
+	return r
+}
+
+// Update применяет лимиты, флаги и переопределения tenant из новой конфигурации
+func (r *Registry) Update(cfg *config.Config) {
+	r.state.Store(&registryState{
+		defaults: Settings{
+			Limits:     cfg.Limits,
+			Features:   cfg.Features,
+			UserLimits: cfg.UserLimits,
+			Quota:      cfg.TenantQuota,
+		},
+		overrides: cfg.Tenancy.Overrides,
+	})
+}
+
+// Settings возвращает настройки tenant: значения из overrides
+// перекрывают значения по умолчанию поле за полем
+func (r *Registry) Settings(tenantID string) Settings {
+	state := r.state.Load()
+	s := Settings{
+		Limits:     state.defaults.Limits,
+		Features:   maps.Clone(state.defaults.Features),
+		UserLimits: maps.Clone(state.defaults.UserLimits),
+		Quota:      state.defaults.Quota,
+	}
+
+	override, ok := state.overrides[tenantID]
+	if !ok {
+		return s
+	}
+
+	s.Limits = s.Limits.Merge(override.Limits)
+	s.Quota = s.Quota.Merge(override.Quota)
+	if len(override.Features) > 0 && s.Features == nil {
+		s.Features = make(map[string]bool, len(override.Features))
+	}
+	maps.Copy(s.Features, override.Features)
+	for userID, l := range override.UserLimits {
+		if s.UserLimits == nil {
+			s.UserLimits = make(map[string]config.LimitsConfig, len(override.UserLimits))
+		}
+		s.UserLimits[userID] = s.UserLimits[userID].Merge(l)
+	}
+
+	return s
+}
+
+// UserLimits возвращает квоты пользователя в tenant.
+// Приоритет: лимиты пользователя, лимиты tenant, значения по умолчанию.
+func (r *Registry) UserLimits(tenantID, userID string) config.LimitsConfig {
+	s := r.Settings(tenantID)
+	return s.Limits.Merge(s.UserLimits[userID])
+}
+
+// TenantQuota возвращает суммарную квоту tenant
+func (r *Registry) TenantQuota(tenantID string) config.LimitsConfig {
+	return r.Settings(tenantID).Quota
+}
+
+// FeatureEnabled сообщает, включен ли флаг для tenant из контекста
+func (r *Registry) FeatureEnabled(ctx context.Context, feature string) bool {
+	id, _ := FromContext(ctx)
+	return r.Settings(id).Features[feature]
+}
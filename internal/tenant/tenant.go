@@ -0,0 +1,125 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"ms_template/internal/auth"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultHeader - ключ metadata, из которого читается tenant по умолчанию
+const DefaultHeader = "x-tenant-id"
+
+// ErrMissing возвращается, если в контексте нет tenant
+var ErrMissing = errors.New("tenant не определен")
+
+type idKey struct{}
+
+// NewContext возвращает контекст с идентификатором tenant
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext возвращает идентификатор tenant из контекста
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Resolver определяет tenant входящего запроса.
+// Приоритет: claim аутентифицированного клиента, затем metadata,
+// затем tenant по умолчанию. Metadata принимается только от
+// аутентифицированных доверенных прокси: иначе любой клиент мог бы
+// обратиться к чужому tenant, передав его идентификатор.
+type Resolver struct {
+	header    string
+	defaultID string
+	required  bool
+	trusted   map[string]struct{}
+}
+
+// NewResolver создает Resolver. Если required, запросы без tenant отклоняются
+// и defaultID не используется. trustedProxies - Subject клиентов, которым
+// разрешено передавать tenant в metadata.
+func NewResolver(header, defaultID string, required bool, trustedProxies []string) *Resolver {
+	if header == "" {
+		header = DefaultHeader
+	}
+	trusted := make(map[string]struct{}, len(trustedProxies))
+	for _, subject := range trustedProxies {
+		trusted[subject] = struct{}{}
+	}
+	return &Resolver{header: header, defaultID: defaultID, required: required, trusted: trusted}
+}
+
+// Resolve возвращает идентификатор tenant для запроса
+func (r *Resolver) Resolve(ctx context.Context) (string, error) {
+	var fromMD string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(r.header); len(values) > 0 {
+			fromMD = values[0]
+		}
+	}
+
+	p, authenticated := auth.FromContext(ctx)
+	if authenticated && p.Tenant != "" {
+		// Клиент с claim не может обратиться к чужому tenant через metadata
+		if fromMD != "" && fromMD != p.Tenant {
+			return "", status.Error(codes.PermissionDenied, "tenant из metadata не совпадает с tenant клиента")
+		}
+		return p.Tenant, nil
+	}
+
+	if fromMD != "" {
+		if _, ok := r.trusted[p.Subject]; !authenticated || !ok {
+			return "", status.Error(codes.PermissionDenied, "tenant из metadata принимается только от доверенных прокси")
+		}
+		return fromMD, nil
+	}
+
+	if r.required || r.defaultID == "" {
+		return "", status.Errorf(codes.Unauthenticated, "не задан tenant: ожидается claim клиента или metadata %q от доверенного прокси", r.header)
+	}
+
+	return r.defaultID, nil
+}
+
+// UnaryServerInterceptor сохраняет tenant запроса в контексте
+func (r *Resolver) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		id, err := r.Resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+// StreamServerInterceptor сохраняет tenant стрима в контексте
+func (r *Resolver) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		id, err := r.Resolve(ss.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = NewContext(ss.Context(), id)
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,97 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"ms_template/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type ResolverTestSuite struct {
+	suite.Suite
+	resolver *Resolver
+}
+
+func TestResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
+
+func (s *ResolverTestSuite) SetupTest() {
+	s.resolver = NewResolver("", "default", false, []string{"gateway"})
+}
+
+func withHeader(ctx context.Context, id string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(DefaultHeader, id))
+}
+
+func (s *ResolverTestSuite) TestResolve_Default() {
+	// Act
+	id, err := s.resolver.Resolve(context.Background())
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "default", id)
+}
+
+func (s *ResolverTestSuite) TestResolve_UnauthenticatedHeaderRejected() {
+	// Arrange
+	ctx := withHeader(context.Background(), "other")
+
+	// Act
+	_, err := s.resolver.Resolve(ctx)
+
+	// Assert
+	assert.Equal(s.T(), codes.PermissionDenied, status.Code(err))
+}
+
+func (s *ResolverTestSuite) TestResolve_UntrustedClientHeaderRejected() {
+	// Arrange
+	ctx := auth.NewContext(withHeader(context.Background(), "other"), auth.Principal{Subject: "client"})
+
+	// Act
+	_, err := s.resolver.Resolve(ctx)
+
+	// Assert
+	assert.Equal(s.T(), codes.PermissionDenied, status.Code(err))
+}
+
+func (s *ResolverTestSuite) TestResolve_TrustedProxyHeader() {
+	// Arrange
+	ctx := auth.NewContext(withHeader(context.Background(), "other"), auth.Principal{Subject: "gateway"})
+
+	// Act
+	id, err := s.resolver.Resolve(ctx)
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "other", id)
+}
+
+func (s *ResolverTestSuite) TestResolve_ClaimMismatch() {
+	// Arrange
+	ctx := auth.NewContext(withHeader(context.Background(), "other"), auth.Principal{Subject: "gateway", Tenant: "acme"})
+
+	// Act
+	_, err := s.resolver.Resolve(ctx)
+
+	// Assert
+	assert.Equal(s.T(), codes.PermissionDenied, status.Code(err))
+}
+
+func (s *ResolverTestSuite) TestResolve_Required() {
+	// Arrange
+	resolver := NewResolver("", "default", true, nil)
+
+	// Act
+	_, err := resolver.Resolve(context.Background())
+
+	// Assert
+	assert.Equal(s.T(), codes.Unauthenticated, status.Code(err))
+}
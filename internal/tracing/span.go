@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+
+	"ms_template/internal/tenant"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TenantIDKey - атрибут span с идентификатором tenant
+const TenantIDKey = attribute.Key("tenant.id")
+
+// Start начинает span от глобального TracerProvider. Tracer берется при каждом
+// вызове, поэтому провайдер, установленный после импорта пакета, тоже учитывается.
+// К span добавляется tenant из контекста, если он есть.
+func Start(ctx context.Context, scope, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(scope).Start(ctx, name, opts...)
+	if id, ok := tenant.FromContext(ctx); ok {
+		span.SetAttributes(TenantIDKey.String(id))
+	}
+	return ctx, span
+}
+
+// End завершает span и отмечает в нем ошибку, если она есть
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
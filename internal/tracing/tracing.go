@@ -0,0 +1,178 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"ms_template/internal/buildinfo"
+	"ms_template/internal/config"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Provider владеет TracerProvider приложения и ресурсами экспортера
+type Provider struct {
+	tp     *sdktrace.TracerProvider // nil, если трассировка отключена
+	closer io.Closer                // файл для exporter: file
+}
+
+// New настраивает трассировку по конфигурации и устанавливает глобальные
+// TracerProvider и propagator, которые используют otelgrpc и слои приложения.
+// opts дополняют настройки провайдера, например span processor в тестах.
+func New(ctx context.Context, log *slog.Logger, cfg config.TracingConfig, env string, opts ...sdktrace.TracerProviderOption) (*Provider, error) {
+	otel.SetTextMapPropagator(Propagator(cfg.Propagators))
+	if !cfg.Enabled {
+		return &Provider{}, nil
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Warn("Ошибка OpenTelemetry", "error", err)
+	}))
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(buildinfo.Get().Version),
+		semconv.DeploymentEnvironmentName(env),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ресурса трассировки: %w", err)
+	}
+
+	// Решение о выборке принимает корневой span, дочерние его наследуют
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*cfg.SampleRatio))),
+	}
+	if exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	tp := sdktrace.NewTracerProvider(append(providerOpts, opts...)...)
+	otel.SetTracerProvider(tp)
+
+	log.Info("Трассировка включена",
+		slog.String("exporter", cfg.Exporter),
+		slog.Float64("sample_ratio", *cfg.SampleRatio),
+		slog.Any("propagators", cfg.Propagators),
+	)
+
+	return &Provider{tp: tp, closer: closer}, nil
+}
+
+// newExporter создает экспортер span; для exporter: none возвращает nil
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+
+	switch cfg.Exporter {
+	case config.TracingExporterNone:
+		return nil, nil, nil
+	case config.TracingExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithHeaders(headers(cfg.Headers)),
+			otlptracegrpc.WithTimeout(cfg.Timeout),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case config.TracingExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.Endpoint),
+			otlptracehttp.WithHeaders(headers(cfg.Headers)),
+			otlptracehttp.WithTimeout(cfg.Timeout),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingExporterFile:
+		f, openErr := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if openErr != nil {
+			return nil, nil, fmt.Errorf("ошибка открытия файла трасс: %w", openErr)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, nil, fmt.Errorf("неизвестный exporter %q", cfg.Exporter)
+	}
+
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, nil, fmt.Errorf("ошибка создания exporter %s: %w", cfg.Exporter, err)
+	}
+	return exporter, closer, nil
+}
+
+// Propagator собирает составной propagator из имен форматов.
+// B3 извлекается в обоих вариантах, имя задает только формат отправки.
+func Propagator(names []string) propagation.TextMapPropagator {
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case config.PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case config.PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case config.PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case config.PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
+
+// Enabled сообщает, создает ли провайдер span
+func (p *Provider) Enabled() bool {
+	return p.tp != nil
+}
+
+// Shutdown отправляет накопленные span и останавливает экспортер
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tp == nil {
+		return nil
+	}
+
+	err := p.tp.Shutdown(ctx)
+	if p.closer != nil {
+		err = errors.Join(err, p.closer.Close())
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка остановки трассировки: %w", err)
+	}
+	return nil
+}
+
+// headers возвращает значения заголовков OTLP
+func headers(secrets map[string]config.Secret) map[string]string {
+	values := make(map[string]string, len(secrets))
+	for name, value := range secrets {
+		values[name] = value.Value()
+	}
+	return values
+}
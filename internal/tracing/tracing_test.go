@@ -0,0 +1,215 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ms_template/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	log        *slog.Logger
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+func (s *TracingTestSuite) SetupTest() {
+	s.log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	s.provider = otel.GetTracerProvider()
+	s.propagator = otel.GetTextMapPropagator()
+}
+
+func (s *TracingTestSuite) TearDownTest() {
+	otel.SetTracerProvider(s.provider)
+	otel.SetTextMapPropagator(s.propagator)
+}
+
+func (s *TracingTestSuite) TestPropagator_InjectFormats() {
+	testCases := []struct {
+		name     string
+		names    []string
+		expected string
+	}{
+		{name: "tracecontext", names: []string{config.PropagatorTraceContext}, expected: "traceparent"},
+		{name: "b3", names: []string{config.PropagatorB3}, expected: "b3"},
+		{name: "b3multi", names: []string{config.PropagatorB3Multi}, expected: "x-b3-traceid"},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			// Arrange
+			ctx := trace.ContextWithSpanContext(context.Background(), spanContext(s.T()))
+			carrier := propagation.MapCarrier{}
+
+			// Act
+			Propagator(tc.names).Inject(ctx, carrier)
+
+			// Assert
+			assert.Contains(s.T(), carrier.Keys(), tc.expected)
+		})
+	}
+}
+
+func (s *TracingTestSuite) TestPropagator_ExtractsB3AndTraceContext() {
+	propagator := Propagator([]string{config.PropagatorTraceContext, config.PropagatorB3})
+
+	carriers := map[string]propagation.MapCarrier{
+		"traceparent": {"traceparent": "00-" + traceID + "-" + spanID + "-01"},
+		"b3":          {"b3": traceID + "-" + spanID + "-1"},
+		"b3 multi":    {"x-b3-traceid": traceID, "x-b3-spanid": spanID, "x-b3-sampled": "1"},
+	}
+
+	for name, carrier := range carriers {
+		s.Run(name, func() {
+			// Act
+			ctx := propagator.Extract(context.Background(), carrier)
+
+			// Assert
+			sc := trace.SpanContextFromContext(ctx)
+			assert.Equal(s.T(), traceID, sc.TraceID().String())
+			assert.True(s.T(), sc.IsRemote())
+		})
+	}
+}
+
+func (s *TracingTestSuite) TestNew_Disabled() {
+	// Act
+	provider, err := New(context.Background(), s.log, config.TracingConfig{}, "local")
+
+	// Assert
+	require.NoError(s.T(), err)
+	assert.False(s.T(), provider.Enabled())
+	assert.NoError(s.T(), provider.Shutdown(context.Background()))
+}
+
+func (s *TracingTestSuite) TestNew_FileExporter() {
+	// Arrange
+	path := filepath.Join(s.T().TempDir(), "traces.json")
+	provider, err := New(context.Background(), s.log, s.config(config.TracingExporterFile, path), "local")
+	require.NoError(s.T(), err)
+
+	// Act
+	_, span := Start(context.Background(), "test", "file-span")
+	span.End()
+	require.NoError(s.T(), provider.Shutdown(context.Background()))
+
+	// Assert
+	data, err := os.ReadFile(path)
+	require.NoError(s.T(), err)
+	assert.Contains(s.T(), string(data), `"Name":"file-span"`)
+	assert.Contains(s.T(), string(data), "notes_service")
+}
+
+func (s *TracingTestSuite) TestNew_SampleRatioZeroKeepsRemoteDecision() {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	cfg := s.config(config.TracingExporterNone, "")
+	ratio := 0.0
+	cfg.SampleRatio = &ratio
+	provider, err := New(context.Background(), s.log, cfg, "local", sdktrace.WithSpanProcessor(recorder))
+	require.NoError(s.T(), err)
+	defer provider.Shutdown(context.Background())
+
+	// Act: корневой span отбрасывается, дочерний к выбранному родителю записывается
+	_, root := Start(context.Background(), "test", "root")
+	root.End()
+	parent := trace.ContextWithRemoteSpanContext(context.Background(), spanContext(s.T()))
+	_, child := Start(parent, "test", "child")
+	child.End()
+
+	// Assert
+	require.Len(s.T(), recorder.Ended(), 1)
+	assert.Equal(s.T(), "child", recorder.Ended()[0].Name())
+}
+
+func (s *TracingTestSuite) TestGRPCServerSpan_ContinuesB3Trace() {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	cfg := s.config(config.TracingExporterNone, "")
+	cfg.Propagators = []string{config.PropagatorTraceContext, config.PropagatorB3}
+	provider, err := New(context.Background(), s.log, cfg, "local", sdktrace.WithSpanProcessor(recorder))
+	require.NoError(s.T(), err)
+	defer provider.Shutdown(context.Background())
+
+	client := s.startServer()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "b3", traceID+"-"+spanID+"-1")
+
+	// Act
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(s.T(), err)
+
+	// Assert
+	require.NoError(s.T(), provider.tp.ForceFlush(context.Background()))
+	require.Len(s.T(), recorder.Ended(), 1)
+	span := recorder.Ended()[0]
+	assert.Equal(s.T(), "grpc.health.v1.Health/Check", span.Name())
+	assert.Equal(s.T(), trace.SpanKindServer, span.SpanKind())
+	assert.Equal(s.T(), traceID, span.SpanContext().TraceID().String())
+	assert.Equal(s.T(), spanID, span.Parent().SpanID().String())
+}
+
+func (s *TracingTestSuite) config(exporter, file string) config.TracingConfig {
+	cfg := config.TracingConfig{Enabled: true, Exporter: exporter, File: file}
+	ratio := 1.0
+	cfg.SampleRatio = &ratio
+	cfg.ServiceName = "notes_service"
+	cfg.Propagators = []string{config.PropagatorTraceContext}
+	return cfg
+}
+
+// startServer поднимает gRPC сервер в памяти с otelgrpc stats.Handler
+func (s *TracingTestSuite) startServer() healthpb.HealthClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	s.T().Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(s.T(), err)
+	s.T().Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func spanContext(t *testing.T) trace.SpanContext {
+	tid, err := trace.TraceIDFromHex(traceID)
+	require.NoError(t, err)
+	sid, err := trace.SpanIDFromHex(spanID)
+	require.NoError(t, err)
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: trace.FlagsSampled})
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	notespb "ms_template/gen/go/notes"
+	"ms_template/internal/app"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TestGetUsage_TenantUsageFeature проверяет, что флаг tenant_usage из
+// features и tenancy.overrides управляет данными tenant в ответе GetUsage
+func TestGetUsage_TenantUsageFeature(t *testing.T) {
+	testCases := []struct {
+		name     string
+		features string
+		expected bool
+	}{
+		{name: "флаг не задан", features: "", expected: false},
+		{name: "включен глобально", features: "features:\n  tenant_usage: true\n", expected: true},
+		{
+			name:     "выключен для tenant",
+			features: "features:\n  tenant_usage: true\ntenancy:\n  overrides:\n    default:\n      features:\n        tenant_usage: false\n",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			client := startNotes(t, tc.features)
+
+			// Act
+			resp, err := client.GetUsage(context.Background(), &notespb.GetUsageRequest{UserID: "user-1"})
+
+			// Assert
+			require.NoError(t, err)
+			assert.NotNil(t, resp.User)
+			assert.Equal(t, tc.expected, resp.Tenant != nil)
+		})
+	}
+}
+
+// startNotes запускает приложение с дополнительной конфигурацией
+// и возвращает клиент сервиса заметок
+func startNotes(t *testing.T, extra string) notespb.NotesClient {
+	t.Helper()
+	grpcPort, metricsPort := freePort(t), freePort(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`env: local
+grpc:
+  port: %d
+  timeout: 5s
+prometheus:
+  port: %d
+shutdown:
+  timeout: 5s
+  drain_delay: 0s
+`, grpcPort, metricsPort) + extra
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+
+	application, err := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.NewLevels(slog.LevelInfo), cfg)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:%d/startupz", metricsPort))
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", grpcPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return notespb.NewNotesClient(conn)
+}
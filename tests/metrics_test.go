@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	notespb "ms_template/gen/go/notes"
+	"ms_template/internal/app"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsTestSuite поднимает приложение целиком и проверяет,
+// что /metrics отдает метрики gRPC и HTTP из общего регистра
+type MetricsTestSuite struct {
+	suite.Suite
+	grpcPort    int
+	metricsPort int
+	cancel      context.CancelFunc
+	done        chan error
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
+
+func (s *MetricsTestSuite) SetupSuite() {
+	s.grpcPort = freePort(s.T())
+	s.metricsPort = freePort(s.T())
+
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`env: local
+grpc:
+  port: %d
+  timeout: 5s
+  tls:
+    mode: insecure
+prometheus:
+  port: %d
+shutdown:
+  timeout: 5s
+  drain_delay: 0s
+`, s.grpcPort, s.metricsPort)
+	require.NoError(s.T(), os.WriteFile(path, []byte(yaml), 0o600))
+
+	cfg, err := config.LoadConfig(path)
+	require.NoError(s.T(), err)
+
+	application, err := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.NewLevels(slog.LevelInfo), cfg)
+	require.NoError(s.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan error, 1)
+	go func() { s.done <- application.Run(ctx) }()
+
+	require.Eventually(s.T(), func() bool {
+		resp, err := http.Get(s.url("/startupz"))
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func (s *MetricsTestSuite) TearDownSuite() {
+	s.cancel()
+	require.NoError(s.T(), <-s.done)
+}
+
+func (s *MetricsTestSuite) TestScrape_ExposesGRPCAndHTTPMetrics() {
+	// Arrange: один успешный вызов gRPC
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", s.grpcPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(s.T(), err)
+	defer conn.Close()
+
+	_, err = notespb.NewNotesClient(conn).GetNotes(context.Background(), &notespb.GetNotesRequest{UserID: "user-1"})
+	require.NoError(s.T(), err)
+
+	// Act: соединение еще открыто
+	body := s.scrape()
+
+	// Assert
+	assert.Contains(s.T(), body, `grpc_requests_total{app="notes_service",code="OK",method="/notes.Notes/GetNotes"} 1`)
+	assert.Contains(s.T(), body, `grpc_request_duration_seconds_count{app="notes_service",code="OK",method="/notes.Notes/GetNotes"} 1`)
+	assert.Contains(s.T(), body, `grpc_requests_in_flight{app="notes_service",method="/notes.Notes/GetNotes"} 0`)
+	assert.Contains(s.T(), body, `grpc_active_connections{app="notes_service"} 1`)
+	assert.Contains(s.T(), body, `grpc_messages_total{app="notes_service",direction="received",method="/notes.Notes/GetNotes"} 1`)
+	assert.Contains(s.T(), body, `grpc_message_size_bytes_count{app="notes_service",direction="sent",method="/notes.Notes/GetNotes"} 1`)
+	assert.Contains(s.T(), body, "go_goroutines")
+	assert.Regexp(s.T(), `build_info\{app="notes_service",commit="[^"]*",date="[^"]*",go_version="go[^"]+",modified="(true|false)",version="[^"]+"\} 1`, body)
+	assert.Contains(s.T(), body, `http_requests_total{app="notes_service",code="200",handler="/startupz",method="get"}`)
+}
+
+func (s *MetricsTestSuite) TestScrape_CountsRejectedTenant() {
+	// Arrange: клиент без аутентификации передает чужой tenant
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", s.grpcPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(s.T(), err)
+	defer conn.Close()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "other")
+
+	// Act
+	_, err = notespb.NewNotesClient(conn).GetUsage(ctx, &notespb.GetUsageRequest{UserID: "user-1"})
+	body := s.scrape()
+
+	// Assert
+	assert.Equal(s.T(), codes.PermissionDenied, status.Code(err))
+	assert.Contains(s.T(), body, `grpc_requests_total{app="notes_service",code="PermissionDenied",method="/notes.Notes/GetUsage"} 1`)
+}
+
+func (s *MetricsTestSuite) TestScrape_CountsOwnRequests() {
+	// Act: второй сбор видит первый запрос к /metrics
+	s.scrape()
+	body := s.scrape()
+
+	// Assert
+	assert.Contains(s.T(), body, `http_requests_total{app="notes_service",code="200",handler="/metrics",method="get"}`)
+	assert.Contains(s.T(), body, "promhttp_metric_handler_requests_total")
+}
+
+func (s *MetricsTestSuite) scrape() string {
+	resp, err := http.Get(s.url("/metrics"))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(s.T(), err)
+	return string(body)
+}
+
+func (s *MetricsTestSuite) url(path string) string {
+	return fmt.Sprintf("http://localhost:%d%s", s.metricsPort, path)
+}
+
+// freePort возвращает свободный TCP порт
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
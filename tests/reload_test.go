@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ms_template/internal/app"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReload_KeepsAdminLogLevel проверяет, что уровень, заданный через admin
+// без ttl, сохраняется после перезагрузки с прежним logging.level,
+// например при обновлении секрета
+func TestReload_KeepsAdminLogLevel(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "admin_token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("old-token"), 0o600))
+	adminPort, metricsPort := freePort(t), freePort(t)
+
+	path := filepath.Join(dir, "config.yaml")
+	yaml := fmt.Sprintf(`env: local
+grpc:
+  port: %d
+  timeout: 5s
+prometheus:
+  port: %d
+shutdown:
+  timeout: 5s
+  drain_delay: 0s
+logging:
+  level: info
+admin:
+  enabled: true
+  token: file://%s
+  http_addr: 127.0.0.1:%d
+secrets:
+  refresh_interval: 20ms
+`, freePort(t), metricsPort, tokenPath, adminPort)
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+	opts := config.LoadOptions{Path: path}
+	loaded, err := config.Load(opts)
+	require.NoError(t, err)
+
+	levels := logger.NewLevels(slog.LevelInfo)
+	application, err := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), levels, loaded.Config)
+	require.NoError(t, err)
+	application.WatchConfig(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	adminURL := fmt.Sprintf("http://127.0.0.1:%d/admin/loglevel", adminPort)
+	setLevel := func(token string) int {
+		req, err := http.NewRequest(http.MethodPut, adminURL, strings.NewReader(`{"level":"debug"}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	require.Eventually(t, func() bool { return setLevel("old-token") == http.StatusOK }, 5*time.Second, 20*time.Millisecond)
+
+	// Act: обновление секрета перезагружает конфигурацию с прежним logging.level
+	require.NoError(t, os.WriteFile(tokenPath, []byte("new-token"), 0o600))
+	require.Eventually(t, func() bool { return setLevel("old-token") == http.StatusForbidden }, 5*time.Second, 20*time.Millisecond)
+
+	// Assert
+	assert.Equal(t, slog.LevelDebug, levels.State().Default.Level)
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ms_template/internal/app"
+	"ms_template/internal/config"
+	"ms_template/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun_StartupFailureReleasesListeners проверяет, что при ошибке запуска
+// gRPC сервера уже открытый листенер метрик закрывается
+func TestRun_StartupFailureReleasesListeners(t *testing.T) {
+	// Arrange: порт gRPC занят
+	busy, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer busy.Close()
+	metricsPort := freePort(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`env: local
+grpc:
+  port: %d
+  timeout: 5s
+prometheus:
+  port: %d
+shutdown:
+  timeout: 5s
+  drain_delay: 0s
+`, busy.Addr().(*net.TCPAddr).Port, metricsPort)
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	application, err := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), logger.NewLevels(slog.LevelInfo), cfg)
+	require.NoError(t, err)
+
+	// Act
+	runErr := application.Run(context.Background())
+
+	// Assert
+	require.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "grpc")
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", metricsPort))
+	require.NoError(t, err, "порт метрик должен быть освобожден")
+	l.Close()
+}